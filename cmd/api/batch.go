@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"greenlight.pvargasb.com/internal/data"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+var errBatchRolledBack = errors.New("batch rolled back")
+
+var batchMethodSafeList = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// atomicBatchPatterns lists the routes whose writes happen entirely inside the
+// database transaction. Uploads, imports, merges and image deletes touch
+// storage outside of it, so a rollback would leave files behind (or remove
+// files that are still referenced) and they are refused in atomic mode.
+var atomicBatchPatterns = []string{
+	"GET /v1/movies",
+	"GET /v1/movies/{id}",
+	"POST /v1/movies",
+	"PUT /v1/movies/{id}",
+	"PATCH /v1/movies/{id}",
+	"DELETE /v1/movies/{id}",
+	"POST /v1/movies/{id}/restore",
+	"GET /v1/movies/{id}/revisions",
+	"POST /v1/movies/{id}/revisions/{version}/revert",
+	"PUT /v1/movies/{id}/{resource}/{key}",
+	"GET /v1/movies/{id}/translations",
+	"DELETE /v1/movies/{id}/translations/{locale}",
+	"GET /v1/movies/{id}/releases",
+	"POST /v1/movies/{id}/releases",
+	"DELETE /v1/movies/{id}/releases/{release_id}",
+	"GET /v1/movies/{id}/certifications",
+	"DELETE /v1/movies/{id}/certifications/{country}",
+	"POST /v1/movies/{id}/credits",
+	"DELETE /v1/movies/{id}/credits/{person_id}",
+	"GET /v1/movies/{id}/reviews",
+	"POST /v1/movies/{id}/reviews",
+}
+
+type batchRequest struct {
+	ID      string            `json:"id"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+type batchResponse struct {
+	ID      string            `json:"id,omitempty"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// batchResponseWriter buffers a sub-request's response so it can be embedded
+// in the batch response.
+type batchResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *batchResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *batchResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *batchResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return w.body.Write(b)
+}
+
+func validateBatch(v *validator.Validator, mux *http.ServeMux, requests []batchRequest, atomic bool, maxSize int) {
+	v.Check(len(requests) >= 1, "requests", "must contain at least 1 request")
+	v.Check(len(requests) <= maxSize, "requests", fmt.Sprintf("must not contain more than %d requests", maxSize))
+
+	for i, request := range requests {
+		key := fmt.Sprintf("requests[%d]", i)
+
+		v.Check(validator.In(request.Method, batchMethodSafeList...), key, "invalid method")
+		v.Check(strings.HasPrefix(request.Path, "/v1/"), key, "path must start with /v1/")
+		v.Check(!strings.HasPrefix(request.Path, "/v1/batch"), key, "batches cannot be nested")
+
+		if atomic {
+			v.Check(validator.In(batchPattern(mux, request), atomicBatchPatterns...), key, "atomic batches may only contain transactional movie requests")
+		}
+	}
+}
+
+// batchPattern returns the mux pattern request would be routed to, or an empty
+// string if it matches no route.
+func batchPattern(mux *http.ServeMux, request batchRequest) string {
+	r, err := http.NewRequest(request.Method, request.Path, nil)
+	if err != nil {
+		return ""
+	}
+
+	_, pattern := mux.Handler(r)
+	return pattern
+}
+
+// batchHandler dispatches each sub-request through mux as the caller. In
+// atomic mode the sub-requests share one database transaction, which is rolled
+// back as soon as one of them fails.
+func (app *application) batchHandler(mux *http.ServeMux) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var input struct {
+			Atomic   bool           `json:"atomic"`
+			Requests []batchRequest `json:"requests"`
+		}
+
+		if err := app.readJSON(w, r, &input); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		v := validator.New()
+		if validateBatch(v, mux, input.Requests, input.Atomic, app.config.batch.maxSize); !v.Valid() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+
+		responses := make([]batchResponse, len(input.Requests))
+
+		if !input.Atomic {
+			for i, request := range input.Requests {
+				responses[i] = app.dispatchBatchRequest(mux, r, request, i)
+			}
+
+			if err := app.writeJSON(w, http.StatusOK, envelope{"responses": responses}, nil); err != nil {
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+		r = r.WithContext(ctx)
+
+		err := app.models.Transaction(ctx, func(models data.Models) error {
+			txApp := *app
+			txApp.models = models
+			txMux := txApp.handlers()
+
+			for i, request := range input.Requests {
+				responses[i] = txApp.dispatchBatchRequest(txMux, r, request, i)
+				if responses[i].Status < 400 {
+					continue
+				}
+
+				for j := i + 1; j < len(input.Requests); j++ {
+					responses[j] = batchSkippedResponse(input.Requests[j])
+				}
+				return errBatchRolledBack
+			}
+
+			return nil
+		})
+		if err != nil && !errors.Is(err, errBatchRolledBack) {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if err := app.writeJSON(w, http.StatusOK, envelope{"responses": responses, "committed": err == nil}, nil); err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+	}
+}
+
+func (app *application) dispatchBatchRequest(mux http.Handler, r *http.Request, request batchRequest, i int) batchResponse {
+	rw := &batchResponseWriter{header: make(http.Header)}
+
+	// The batch request itself paid for the first item, so a batch of n
+	// requests costs n tokens.
+	if i > 0 && !app.allowRequest(r) {
+		app.rateLimitExceededResponse(rw, r)
+		return newBatchResponse(request, rw)
+	}
+
+	sub, err := http.NewRequestWithContext(r.Context(), request.Method, request.Path, bytes.NewReader(request.Body))
+	if err != nil {
+		app.badRequestResponse(rw, r, err)
+		return newBatchResponse(request, rw)
+	}
+
+	sub.RemoteAddr = r.RemoteAddr
+	if len(request.Body) > 0 {
+		sub.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range request.Headers {
+		sub.Header.Set(key, value)
+	}
+	sub.Header.Del("Authorization")
+
+	app.recoverPanic(app.negotiateRuntimeFormat(mux)).ServeHTTP(rw, sub)
+
+	return newBatchResponse(request, rw)
+}
+
+func newBatchResponse(request batchRequest, rw *batchResponseWriter) batchResponse {
+	response := batchResponse{
+		ID:      request.ID,
+		Status:  rw.status,
+		Headers: make(map[string]string),
+	}
+	if response.Status == 0 {
+		response.Status = http.StatusOK
+	}
+
+	for key := range rw.header {
+		response.Headers[key] = rw.header.Get(key)
+	}
+
+	body := bytes.TrimSpace(rw.body.Bytes())
+	switch {
+	case len(body) == 0:
+	case json.Valid(body):
+		response.Body = body
+	default:
+		response.Body, _ = json.Marshal(string(body))
+	}
+
+	return response
+}
+
+func batchSkippedResponse(request batchRequest) batchResponse {
+	body, _ := json.Marshal(envelope{"error": "not executed because an earlier request in the atomic batch failed"})
+
+	return batchResponse{
+		ID:     request.ID,
+		Status: http.StatusFailedDependency,
+		Body:   body,
+	}
+}
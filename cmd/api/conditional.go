@@ -0,0 +1,112 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"greenlight.pvargasb.com/internal/data"
+)
+
+func movieETag(movie *data.Movie) string {
+	return fmt.Sprintf(`"%d-%d"`, movie.ID, movie.Version)
+}
+
+// movieRepresentationETag tags a rendered movie with its version followed by a
+// hash of the response body. Ratings, credits, translations and the caller's
+// watchlist all change the body without bumping the version, and so do the
+// negotiated locale and the requested fields.
+func movieRepresentationETag(movie *data.Movie, body envelope) (string, error) {
+	hash, err := hashBody(body)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(`"%d-%d-%s"`, movie.ID, movie.Version, hash), nil
+}
+
+func hashBody(body envelope) (string, error) {
+	js, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(js)
+
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		if candidate == "*" {
+			return true
+		}
+
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+
+		if candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// movieETagMatches reports whether header names the movie's current version,
+// either by its version tag or by the tag of any representation of it.
+func movieETagMatches(header string, movie *data.Movie) bool {
+	etag := movieETag(movie)
+	prefix := strings.TrimSuffix(etag, `"`) + "-"
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		if candidate == "*" || candidate == etag || strings.HasPrefix(candidate, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (app *application) checkNotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+
+	header := r.Header.Get("If-None-Match")
+	if header == "" || !etagMatches(header, etag, true) {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+func (app *application) checkMoviePreconditions(w http.ResponseWriter, r *http.Request, movie *data.Movie) bool {
+	ifMatch := r.Header.Get("If-Match")
+	expectedVersion := r.Header.Get("X-Expected-Version")
+
+	if ifMatch != "" && !movieETagMatches(ifMatch, movie) {
+		app.preconditionFailedResponse(w, r)
+		return false
+	}
+
+	if expectedVersion != "" && strconv.Itoa(movie.Version) != expectedVersion {
+		app.editConflictResponse(w, r)
+		return false
+	}
+
+	if ifMatch == "" && expectedVersion == "" && app.config.preconditions.requireIfMatch {
+		app.preconditionRequiredResponse(w, r)
+		return false
+	}
+
+	return true
+}
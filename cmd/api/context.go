@@ -9,7 +9,10 @@ import (
 
 type contextKey string
 
-const userContextKey = contextKey("user")
+const (
+	userContextKey          = contextKey("user")
+	runtimeFormatContextKey = contextKey("runtimeFormat")
+)
 
 func (app *application) contextSetUser(r *http.Request, user *data.User) *http.Request {
 	ctx := context.WithValue(r.Context(), userContextKey, user)
@@ -25,3 +28,18 @@ func (app *application) contextGetUser(r *http.Request) *data.User {
 
 	return user
 }
+
+func (app *application) contextSetRuntimeFormat(r *http.Request, format data.RuntimeFormat) *http.Request {
+	ctx := context.WithValue(r.Context(), runtimeFormatContextKey, format)
+
+	return r.WithContext(ctx)
+}
+
+func (app *application) contextGetRuntimeFormat(r *http.Request) data.RuntimeFormat {
+	format, ok := r.Context().Value(runtimeFormatContextKey).(data.RuntimeFormat)
+	if !ok {
+		return data.RuntimeFormatMins
+	}
+
+	return format
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"net/http"
 )
 
@@ -39,11 +40,54 @@ func (app *application) failedValidationResponse(w http.ResponseWriter, r *http.
 	app.errorResponse(w, r, http.StatusUnprocessableEntity, errors)
 }
 
+func (app *application) unsupportedMediaTypeResponse(w http.ResponseWriter, r *http.Request, contentType string) {
+	message := fmt.Sprintf("the %q content type is not supported by this resource", contentType)
+	app.errorResponse(w, r, http.StatusUnsupportedMediaType, message)
+}
+
+func (app *application) notAcceptableResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the requested representation is not available for this resource"
+	app.errorResponse(w, r, http.StatusNotAcceptable, message)
+}
+
 func (app *application) editConflictResponse(w http.ResponseWriter, r *http.Request) {
 	message := "unable to update the record due to an edit conflict, please try again"
 	app.errorResponse(w, r, http.StatusConflict, message)
 }
 
+func (app *application) duplicateMovieResponse(w http.ResponseWriter, r *http.Request, candidates []int) {
+	message := "a movie with this title and year already exists, set force=true to create it anyway"
+
+	err := app.writeJSON(w, http.StatusConflict, envelope{"error": message, "candidates": candidates}, nil)
+	if err != nil {
+		app.logError(r, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (app *application) movieInTrashResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the movie linked to this external id is in the trash, restore it before updating"
+	app.errorResponse(w, r, http.StatusConflict, message)
+}
+
+func (app *application) invalidPatchResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.errorResponse(w, r, http.StatusUnprocessableEntity, err.Error())
+}
+
+func (app *application) patchTestFailedResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.errorResponse(w, r, http.StatusConflict, err.Error())
+}
+
+func (app *application) preconditionFailedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the resource has been modified since the version provided in If-Match"
+	app.errorResponse(w, r, http.StatusPreconditionFailed, message)
+}
+
+func (app *application) preconditionRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	message := "this request must be made conditional with an If-Match header"
+	app.errorResponse(w, r, http.StatusPreconditionRequired, message)
+}
+
 func (app *application) rateLimitExceededResponse(w http.ResponseWriter, r *http.Request) {
 	message := "rate limit exceeded"
 	app.errorResponse(w, r, http.StatusTooManyRequests, message)
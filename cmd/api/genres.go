@@ -0,0 +1,224 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"greenlight.pvargasb.com/internal/data"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+func (app *application) genreRegistry() (*data.GenreRegistry, error) {
+	return app.models.Genres.Registry(app.config.genres.strict)
+}
+
+func (app *application) listGenresHandler(w http.ResponseWriter, r *http.Request) {
+	genres, err := app.models.Genres.GetAll()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"genres": genres}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) createGenreHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Slug    string   `json:"slug"`
+		Name    string   `json:"name"`
+		Aliases []string `json:"aliases"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	genre := &data.Genre{
+		Slug:    input.Slug,
+		Name:    input.Name,
+		Aliases: input.Aliases,
+	}
+	if genre.Slug == "" {
+		genre.Slug = data.Slugify(genre.Name)
+	}
+	if genre.Aliases == nil {
+		genre.Aliases = []string{}
+	}
+
+	registry, err := app.genreRegistry()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	data.ValidateGenre(v, genre)
+	for _, name := range append([]string{genre.Slug}, genre.Aliases...) {
+		if existing, ok := registry.Canonical(name); ok {
+			v.AddError("aliases", fmt.Sprintf("%q already refers to genre %q", name, existing))
+		}
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Genres.Insert(genre); err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateGenre):
+			v.AddError("slug", "a genre with this slug already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrDuplicateGenreAlias):
+			v.AddError("aliases", "an alias is already in use")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/genres/%s", genre.Slug))
+
+	if err := app.writeJSON(w, http.StatusCreated, envelope{"genre": genre}, headers); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) addGenreAliasHandler(w http.ResponseWriter, r *http.Request) {
+	genre, err := app.models.Genres.Get(r.PathValue("slug"))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	var input struct {
+		Alias string `json:"alias"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	registry, err := app.genreRegistry()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(data.Slugify(input.Alias) != "", "alias", "must be provided")
+	if existing, ok := registry.Canonical(input.Alias); ok {
+		v.AddError("alias", fmt.Sprintf("already refers to genre %q", existing))
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Genres.AddAlias(genre, input.Alias); err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateGenreAlias):
+			v.AddError("alias", "is already in use")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"genre": genre}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) mergeGenreHandler(w http.ResponseWriter, r *http.Request) {
+	source, err := app.models.Genres.Get(r.PathValue("slug"))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	var input struct {
+		Into string `json:"into"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.Into != "", "into", "must be provided")
+	v.Check(input.Into != source.Slug, "into", "must be a different genre")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	target, err := app.models.Genres.Get(input.Into)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("into", "genre does not exist")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	var movies []*data.Movie
+	err = app.models.Transaction(r.Context(), func(models data.Models) error {
+		movies, err = models.Genres.Merge(source, target)
+		if err != nil {
+			return err
+		}
+
+		for _, movie := range movies {
+			if err := app.recordMovieRevision(models, r, data.RevisionUpdate, movie); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateGenreAlias):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	target, err = app.models.Genres.Get(target.Slug)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"genre": target, "movies_updated": len(movies)}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
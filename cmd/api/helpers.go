@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 
+	"greenlight.pvargasb.com/internal/data"
 	"greenlight.pvargasb.com/internal/validator"
 )
 
@@ -56,6 +58,56 @@ func (app *application) writeJSON(w http.ResponseWriter, status int, data envelo
 	return nil
 }
 
+// setRuntimeFormat renders the runtime of each movie in the format negotiated
+// for the request.
+func (app *application) setRuntimeFormat(r *http.Request, movies ...*data.Movie) {
+	format := app.contextGetRuntimeFormat(r)
+	for _, movie := range movies {
+		movie.SetRuntimeFormat(format)
+	}
+}
+
+func (app *application) selectFields(value any, fields []string) (any, error) {
+	if len(fields) == 0 {
+		return value, nil
+	}
+
+	js, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	pick := func(all map[string]json.RawMessage) map[string]json.RawMessage {
+		selected := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := all[field]; ok {
+				selected[field] = value
+			}
+		}
+		return selected
+	}
+
+	if bytes.HasPrefix(js, []byte("[")) {
+		var all []map[string]json.RawMessage
+		if err := json.Unmarshal(js, &all); err != nil {
+			return nil, err
+		}
+
+		selected := make([]map[string]json.RawMessage, len(all))
+		for i := range all {
+			selected[i] = pick(all[i])
+		}
+		return selected, nil
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(js, &all); err != nil {
+		return nil, err
+	}
+
+	return pick(all), nil
+}
+
 func (app *application) readJSON(w http.ResponseWriter, r *http.Request, target any) error {
 	const MB = 1024 * 1024
 
@@ -134,6 +186,51 @@ func (app *application) readInt(qs url.Values, key string, defaultValue int, v *
 	return intResult
 }
 
+func (app *application) readFloat(qs url.Values, key string, defaultValue float64, v *validator.Validator) float64 {
+	result := qs.Get(key)
+	if result == "" {
+		return defaultValue
+	}
+
+	floatResult, err := strconv.ParseFloat(result, 64)
+	if err != nil {
+		v.AddError(key, "must be a number")
+		return defaultValue
+	}
+
+	return floatResult
+}
+
+func (app *application) readBool(qs url.Values, key string, defaultValue bool, v *validator.Validator) bool {
+	result := qs.Get(key)
+	if result == "" {
+		return defaultValue
+	}
+
+	boolResult, err := strconv.ParseBool(result)
+	if err != nil {
+		v.AddError(key, "must be a boolean value")
+		return defaultValue
+	}
+
+	return boolResult
+}
+
+func (app *application) readDate(qs url.Values, key string, v *validator.Validator) data.Date {
+	result := qs.Get(key)
+	if result == "" {
+		return data.Date{}
+	}
+
+	date, err := data.ParseDate(result)
+	if err != nil {
+		v.AddError(key, "must be a date in YYYY-MM-DD format")
+		return data.Date{}
+	}
+
+	return date
+}
+
 func (app *application) background(fn func()) {
 	app.wg.Add(1)
 	go func() {
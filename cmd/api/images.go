@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"greenlight.pvargasb.com/internal/data"
+	"greenlight.pvargasb.com/internal/images"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+const imageMaxBytes = 10 << 20
+
+var imageWidths = map[string][]int{
+	data.ImageKindPoster: {92, 185, 342, 780},
+	data.ImageKindStill:  {300, 780, 1280},
+}
+
+var errUnsupportedUpload = errors.New("unsupported upload content type")
+
+func movieMediaPrefix(movieID int) string {
+	return fmt.Sprintf("movies/%d", movieID)
+}
+
+func (app *application) updatePosterHandler(w http.ResponseWriter, r *http.Request) {
+	movie, ok := app.readMovieParam(w, r)
+	if !ok {
+		return
+	}
+
+	if !app.checkMoviePreconditions(w, r, movie) {
+		return
+	}
+
+	image, ok := app.readImageUpload(w, r, movie.ID, data.ImageKindPoster)
+	if !ok {
+		return
+	}
+
+	var previous string
+	err := app.models.Transaction(r.Context(), func(models data.Models) error {
+		var err error
+		previous, err = models.Images.ReplacePoster(movie, image)
+		if err != nil {
+			return err
+		}
+
+		return app.recordMovieRevision(models, r, data.RevisionUpdate, movie)
+	})
+	if err != nil {
+		app.deleteStoredFiles(image.Prefix)
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if previous != "" {
+		app.deleteStoredFiles(previous)
+	}
+
+	headers := make(http.Header)
+	headers.Set("ETag", movieETag(movie))
+
+	app.setRuntimeFormat(r, movie)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, headers); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) createStillHandler(w http.ResponseWriter, r *http.Request) {
+	movie, ok := app.readMovieParam(w, r)
+	if !ok {
+		return
+	}
+
+	image, ok := app.readImageUpload(w, r, movie.ID, data.ImageKindStill)
+	if !ok {
+		return
+	}
+
+	if err := app.models.Images.Insert(image); err != nil {
+		app.deleteStoredFiles(image.Prefix)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusCreated, envelope{"image": image}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) listMovieImagesHandler(w http.ResponseWriter, r *http.Request) {
+	movie, ok := app.readMovieParam(w, r)
+	if !ok {
+		return
+	}
+
+	images, err := app.models.Images.GetAllForMovie(movie.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"images": images}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) deleteMovieImageHandler(w http.ResponseWriter, r *http.Request) {
+	movie, ok := app.readMovieParam(w, r)
+	if !ok {
+		return
+	}
+
+	if !app.checkMoviePreconditions(w, r, movie) {
+		return
+	}
+
+	imageID, err := strconv.ParseInt(r.PathValue("image_id"), 10, 64)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// Removing the poster changes the movie, so it is recorded as a revision.
+	var image *data.Image
+	err = app.models.Transaction(r.Context(), func(models data.Models) error {
+		image, err = models.Images.Delete(movie, imageID)
+		if err != nil {
+			return err
+		}
+
+		if image.Kind != data.ImageKindPoster {
+			return nil
+		}
+
+		return app.recordMovieRevision(models, r, data.RevisionUpdate, movie)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.deleteStoredFiles(image.Prefix)
+
+	headers := make(http.Header)
+	if image.Kind == data.ImageKindPoster {
+		headers.Set("ETag", movieETag(movie))
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"message": "image deleted"}, headers); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) readMovieParam(w http.ResponseWriter, r *http.Request) (*data.Movie, bool) {
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	movie, err := app.models.Movies.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, false
+	}
+
+	return movie, true
+}
+
+// readImageUpload reads a multipart ("image" field) or raw image body,
+// validates it and stores the original along with its resized variants.
+func (app *application) readImageUpload(w http.ResponseWriter, r *http.Request, movieID int, kind string) (*data.Image, bool) {
+	b, err := readUploadBody(w, r)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		switch {
+		case errors.Is(err, errUnsupportedUpload):
+			app.unsupportedMediaTypeResponse(w, r, r.Header.Get("Content-Type"))
+		case errors.As(err, &maxBytesError):
+			app.badRequestResponse(w, r, fmt.Errorf("image must not be larger than %d bytes", imageMaxBytes))
+		default:
+			app.badRequestResponse(w, r, err)
+		}
+		return nil, false
+	}
+
+	cfg, err := images.DecodeConfig(b)
+	if err != nil {
+		switch {
+		case errors.Is(err, images.ErrUnsupportedFormat):
+			app.unsupportedMediaTypeResponse(w, r, http.DetectContentType(b))
+		default:
+			app.badRequestResponse(w, r, errors.New("image is corrupt or truncated"))
+		}
+		return nil, false
+	}
+
+	image := &data.Image{
+		MovieID:     movieID,
+		Kind:        kind,
+		ContentType: cfg.ContentType,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}
+
+	v := validator.New()
+	if data.ValidateImage(v, image); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return nil, false
+	}
+
+	if err := app.storeImage(r.Context(), image, b, cfg.Format); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return nil, false
+	}
+
+	return image, true
+}
+
+func readUploadBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, imageMaxBytes)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "multipart/form-data":
+		if err := r.ParseMultipartForm(imageMaxBytes); err != nil {
+			return nil, err
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		file, _, err := r.FormFile("image")
+		if err != nil {
+			return nil, errors.New(`body must contain an "image" file field`)
+		}
+		defer file.Close()
+
+		return io.ReadAll(file)
+	case strings.HasPrefix(mediaType, "image/"):
+		return io.ReadAll(r.Body)
+	default:
+		return nil, errUnsupportedUpload
+	}
+}
+
+func (app *application) storeImage(ctx context.Context, image *data.Image, b []byte, format string) error {
+	token := make([]byte, 8)
+	if _, err := rand.Read(token); err != nil {
+		return err
+	}
+
+	image.Prefix = fmt.Sprintf("%s/%s-%s", movieMediaPrefix(image.MovieID), image.Kind, hex.EncodeToString(token))
+	image.Thumbnails = make(map[string]string)
+
+	original := fmt.Sprintf("%s/original.%s", image.Prefix, images.Extension(format))
+	if err := app.storage.Put(ctx, original, bytes.NewReader(b)); err != nil {
+		return err
+	}
+	image.URL = app.storage.URL(original)
+
+	img, err := images.Decode(b)
+	if err != nil {
+		app.deleteStoredFiles(image.Prefix)
+		return err
+	}
+
+	for _, width := range imageWidths[image.Kind] {
+		if width >= image.Width {
+			continue
+		}
+
+		var buf bytes.Buffer
+		ext, err := images.Encode(&buf, images.Resize(img, width), format)
+		if err != nil {
+			app.deleteStoredFiles(image.Prefix)
+			return err
+		}
+
+		key := fmt.Sprintf("%s/w%d.%s", image.Prefix, width, ext)
+		if err := app.storage.Put(ctx, key, &buf); err != nil {
+			app.deleteStoredFiles(image.Prefix)
+			return err
+		}
+		image.Thumbnails[fmt.Sprintf("w%d", width)] = app.storage.URL(key)
+	}
+
+	return nil
+}
+
+func (app *application) deleteStoredFiles(prefix string) {
+	if err := app.storage.Delete(context.Background(), prefix); err != nil {
+		app.logger.Error(err, map[string]string{"prefix": prefix})
+	}
+}
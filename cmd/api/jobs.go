@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strconv"
+	"time"
+)
+
+const trashPurgeInterval = time.Hour
+
+func (app *application) startTrashPurger() {
+	if app.config.trash.retentionDays <= 0 {
+		return
+	}
+
+	retention := time.Duration(app.config.trash.retentionDays) * 24 * time.Hour
+
+	app.background(func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			app.purgeTrash(retention)
+
+			select {
+			case <-app.shutdown:
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+func (app *application) purgeTrash(retention time.Duration) {
+	ids, prefixes, err := app.models.Movies.PurgeDeleted(retention)
+	if err != nil {
+		app.logger.Error(err, nil)
+		return
+	}
+
+	for _, id := range ids {
+		app.deleteStoredFiles(movieMediaPrefix(id))
+	}
+	for _, prefix := range prefixes {
+		app.deleteStoredFiles(prefix)
+	}
+
+	if len(ids) > 0 {
+		app.logger.Info("purged trashed movies", map[string]string{
+			"count": strconv.Itoa(len(ids)),
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"greenlight.pvargasb.com/internal/data"
 	"greenlight.pvargasb.com/internal/jsonlog"
 	"greenlight.pvargasb.com/internal/mailer"
+	"greenlight.pvargasb.com/internal/storage"
 )
 
 var (
@@ -34,9 +35,11 @@ type config struct {
 		maxIdleTime  string
 	}
 	limiter struct {
-		rps     float64
-		burst   int
-		enabled bool
+		rps               float64
+		burst             int
+		autocompleteRPS   float64
+		autocompleteBurst int
+		enabled           bool
 	}
 	smtp struct {
 		host     string
@@ -48,14 +51,35 @@ type config struct {
 	cors struct {
 		trustedOrigins []string
 	}
+	trash struct {
+		retentionDays int
+	}
+	batch struct {
+		maxSize int
+	}
+	preconditions struct {
+		requireIfMatch bool
+	}
+	genres struct {
+		strict bool
+	}
+	storage struct {
+		dir     string
+		baseURL string
+	}
 }
 
 type application struct {
-	wg     sync.WaitGroup
-	models data.Models
-	config config
-	logger *jsonlog.Logger
-	mailer mailer.Mailer
+	wg       *sync.WaitGroup
+	shutdown chan struct{}
+	models   data.Models
+	config   config
+	logger   *jsonlog.Logger
+	mailer   mailer.Mailer
+	storage  storage.Storage
+	limiter  *rateLimiter
+
+	autocompleteLimiter *rateLimiter
 }
 
 func main() {
@@ -108,6 +132,18 @@ func main() {
 		4,
 		"Rate limiter maximum burst",
 	)
+	flag.Float64Var(
+		&config.limiter.autocompleteRPS,
+		"limiter-autocomplete-rps",
+		10,
+		"Rate limiter maximum autocomplete requests per second",
+	)
+	flag.IntVar(
+		&config.limiter.autocompleteBurst,
+		"limiter-autocomplete-burst",
+		20,
+		"Rate limiter maximum autocomplete burst",
+	)
 	flag.BoolVar(&config.limiter.enabled,
 		"limiter-enabled",
 		true,
@@ -148,6 +184,42 @@ func main() {
 			return nil
 		},
 	)
+	flag.IntVar(
+		&config.trash.retentionDays,
+		"trash-retention-days",
+		30,
+		"Days to keep deleted movies before purging them (0 disables purging)",
+	)
+	flag.BoolVar(
+		&config.preconditions.requireIfMatch,
+		"require-if-match",
+		false,
+		"Reject movie writes without an If-Match or X-Expected-Version header",
+	)
+	flag.BoolVar(
+		&config.genres.strict,
+		"genres-strict",
+		false,
+		"Reject movie genres that are not in the genre registry",
+	)
+	flag.IntVar(
+		&config.batch.maxSize,
+		"batch-max-size",
+		20,
+		"Maximum number of requests in a batch",
+	)
+	flag.StringVar(
+		&config.storage.dir,
+		"storage-dir",
+		"./uploads",
+		"Directory for uploaded movie images",
+	)
+	flag.StringVar(
+		&config.storage.baseURL,
+		"storage-base-url",
+		"/media",
+		"Base URL that uploaded movie images are served from; its path is where they are mounted",
+	)
 	displayVersion := flag.Bool(
 		"version",
 		false,
@@ -172,6 +244,11 @@ func main() {
 		"dsn": config.db.dsn,
 	})
 
+	store, err := storage.NewLocal(config.storage.dir, config.storage.baseURL)
+	if err != nil {
+		logger.Fatal(err, nil)
+	}
+
 	expvar.NewString("version").Set(version)
 	expvar.Publish("goroutines", expvar.Func(func() any {
 		return runtime.NumGoroutine()
@@ -184,10 +261,16 @@ func main() {
 	}))
 
 	app := application{
-		models: *data.NewModels(db),
-		config: config,
-		logger: logger,
-		mailer: mailer.New(config.smtp.host, config.smtp.port, config.smtp.username, config.smtp.password, config.smtp.sender),
+		wg:       &sync.WaitGroup{},
+		shutdown: make(chan struct{}),
+		models:   *data.NewModels(db),
+		config:   config,
+		logger:   logger,
+		mailer:   mailer.New(config.smtp.host, config.smtp.port, config.smtp.username, config.smtp.password, config.smtp.sender),
+		storage:  store,
+		limiter:  newRateLimiter(config.limiter.rps, config.limiter.burst),
+
+		autocompleteLimiter: newRateLimiter(config.limiter.autocompleteRPS, config.limiter.autocompleteBurst),
 	}
 
 	if err := app.serve(); err != nil {
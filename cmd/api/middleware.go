@@ -22,6 +22,12 @@ func (app *application) recoverPanic(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
+				// A handler that has already started its response aborts the
+				// connection instead, so let net/http see that panic.
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
 				w.Header().Set("Connection", "close")
 				app.serverErrorResponse(w, r, fmt.Errorf("%s", err))
 			}
@@ -31,52 +37,77 @@ func (app *application) recoverPanic(next http.Handler) http.Handler {
 	})
 }
 
-func (app *application) rateLimit(next http.Handler) http.Handler {
-	type client struct {
-		limiter  *rate.Limiter
-		lastSeen time.Time
-	}
+type rateLimiter struct {
+	rps     float64
+	burst   int
+	clients map[string]*rateLimiterClient
+	sync.Mutex
+}
 
-	limiters := struct {
-		clients map[string]*client
-		sync.Mutex
-	}{
-		clients: make(map[string]*client),
+type rateLimiterClient struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	l := &rateLimiter{
+		rps:     rps,
+		burst:   burst,
+		clients: make(map[string]*rateLimiterClient),
 	}
 
 	go func() {
 		for {
 			time.Sleep(1 * time.Minute)
 
-			limiters.Lock()
-			for ip, client := range limiters.clients {
+			l.Lock()
+			for ip, client := range l.clients {
 				if time.Since(client.lastSeen) > 3*time.Minute {
-					delete(limiters.clients, ip)
+					delete(l.clients, ip)
 				}
 			}
-			limiters.Unlock()
+			l.Unlock()
 		}
 	}()
 
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if app.config.limiter.enabled {
-			ip := realip.FromRequest(r)
-
-			limiters.Lock()
-			_, ok := limiters.clients[ip]
-			if !ok {
-				limiters.clients[ip] = &client{
-					limiter: rate.NewLimiter(rate.Limit(app.config.limiter.rps), app.config.limiter.burst),
-				}
-			}
-			limiters.clients[ip].lastSeen = time.Now()
+	return l
+}
 
-			if !limiters.clients[ip].limiter.Allow() {
-				app.rateLimitExceededResponse(w, r)
-				limiters.Unlock()
-				return
-			}
-			limiters.Unlock()
+func (l *rateLimiter) allow(ip string) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	if _, ok := l.clients[ip]; !ok {
+		l.clients[ip] = &rateLimiterClient{
+			limiter: rate.NewLimiter(rate.Limit(l.rps), l.burst),
+		}
+	}
+	l.clients[ip].lastSeen = time.Now()
+
+	return l.clients[ip].limiter.Allow()
+}
+
+// allowRequest takes a token from the client's bucket. Autocomplete is called
+// on every keystroke, so it has a bucket of its own rather than draining the
+// one shared by the rest of the API.
+func (app *application) allowRequest(r *http.Request) bool {
+	if !app.config.limiter.enabled {
+		return true
+	}
+
+	limiter := app.limiter
+	if r.URL.Path == "/v1/movies/autocomplete" {
+		limiter = app.autocompleteLimiter
+	}
+
+	return limiter.allow(realip.FromRequest(r))
+}
+
+func (app *application) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !app.allowRequest(r) {
+			app.rateLimitExceededResponse(w, r)
+			return
 		}
 
 		next.ServeHTTP(w, r)
@@ -85,7 +116,7 @@ func (app *application) rateLimit(next http.Handler) http.Handler {
 
 func (app *application) authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Add("Vary", "Authentication")
+		w.Header().Add("Vary", "Authorization")
 
 		authorizationHeader := r.Header.Get("Authorization")
 		if authorizationHeader == "" {
@@ -174,6 +205,35 @@ func (app *application) requirePermission(code string, next http.HandlerFunc) ht
 	return app.requireActivatedUser(middleware)
 }
 
+// negotiateRuntimeFormat reads the runtime output format from the
+// runtime_format query string parameter or the X-Runtime-Format header.
+func (app *application) negotiateRuntimeFormat(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "X-Runtime-Format")
+
+		format := r.URL.Query().Get("runtime_format")
+		if format == "" {
+			format = r.Header.Get("X-Runtime-Format")
+		}
+		if format == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		format = strings.ToLower(strings.TrimSpace(format))
+
+		v := validator.New()
+		if v.Check(validator.In(format, data.RuntimeFormatSafeList...), "runtime_format", "must be one of mins, hm, iso8601 or seconds"); !v.Valid() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+
+		r = app.contextSetRuntimeFormat(r, data.RuntimeFormat(format))
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (app *application) enableCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Vary", "Origin")
@@ -187,11 +247,12 @@ func (app *application) enableCORS(next http.Handler) http.Handler {
 
 		if slices.Contains(app.config.cors.trustedOrigins, origin) {
 			w.Header().Add("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Access-Control-Expose-Headers", "ETag")
 		}
 
 		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
 			w.Header().Set("Access-Control-Allow-Methods", "OPTIONS, PUT, PATCH, DELETE")
-			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, If-Match, If-None-Match, X-Expected-Version, X-Runtime-Format")
 
 			w.WriteHeader(http.StatusOK)
 			return
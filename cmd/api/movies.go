@@ -4,7 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
+	"net/url"
+	"strings"
 
 	"greenlight.pvargasb.com/internal/data"
 	"greenlight.pvargasb.com/internal/validator"
@@ -12,10 +13,11 @@ import (
 
 func (app *application) createMovieHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Title   string       `json:"title"`
-		Year    int          `json:"year"`
-		Runtime data.Runtime `json:"runtime"`
-		Genres  []string     `json:"genres"`
+		Title     string       `json:"title"`
+		Year      int          `json:"year"`
+		Announced bool         `json:"announced"`
+		Runtime   data.Runtime `json:"runtime"`
+		Genres    []string     `json:"genres"`
 	}
 
 	if err := app.readJSON(w, r, &input); err != nil {
@@ -24,25 +26,60 @@ func (app *application) createMovieHandler(w http.ResponseWriter, r *http.Reques
 	}
 
 	movie := data.Movie{
-		Title:   input.Title,
-		Year:    input.Year,
-		Runtime: input.Runtime,
-		Genres:  input.Genres,
+		Title:     input.Title,
+		Year:      input.Year,
+		Announced: input.Announced,
+		Runtime:   input.Runtime,
+		Genres:    input.Genres,
 	}
 
+	genres, err := app.genreRegistry()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	qs := r.URL.Query()
+
 	v := validator.New()
-	if data.ValidateMovie(v, movie); !v.Valid() {
+	force := app.readBool(qs, "force", false, v)
+	fuzzy := app.readBool(qs, "fuzzy", false, v)
+
+	if data.ValidateMovie(v, &movie, genres); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
-	if err := app.models.Movies.Insert(&movie); err != nil {
+	if !force {
+		candidates, err := app.models.Movies.FindDuplicates(movie.Title, movie.Year, fuzzy)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if len(candidates) > 0 {
+			app.duplicateMovieResponse(w, r, candidates)
+			return
+		}
+	}
+
+	err = app.models.Transaction(r.Context(), func(models data.Models) error {
+		if err := models.Movies.Insert(&movie); err != nil {
+			return err
+		}
+
+		return app.recordMovieRevision(models, r, data.RevisionInsert, &movie)
+	})
+	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/movies/%d", movie.ID))
+	headers.Set("ETag", movieETag(&movie))
+
+	app.setRuntimeFormat(r, &movie)
 
 	if err := app.writeJSON(w, http.StatusCreated, envelope{"movie": movie}, headers); err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -57,11 +94,20 @@ func (app *application) showMovieHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	movie, err := app.models.Movies.Get(id)
+	v := validator.New()
+	fields := app.readCSV(r.URL.Query(), "fields", []string{})
+	if data.ValidateFields(v, fields, data.MovieFieldSafeList); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	movie, err := app.models.Movies.GetFields(id, fields)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
-			app.notFoundResponse(w, r)
+			if !app.redirectMergedMovie(w, r, id) {
+				app.notFoundResponse(w, r)
+			}
 			return
 		default:
 			app.serverErrorResponse(w, r, err)
@@ -69,9 +115,61 @@ func (app *application) showMovieHandler(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, nil)
+	movie.ExternalIDs, err = app.models.ExternalIDs.GetAllForMovie(movie.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.localizeMovies(w, r, movie); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.setRuntimeFormat(r, movie)
+
+	output, err := app.selectFields(movie, fields)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	onWatchlist, err := app.models.Watchlist.Contains(app.contextGetUser(r).ID, movie.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	credits, err := app.models.Credits.GetAllForMovie(movie.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	collections, err := app.models.Collections.GetAllForMovie(movie.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	body := envelope{"movie": output, "credits": credits, "collections": collections, "on_watchlist": onWatchlist}
+
+	etag, err := movieRepresentationETag(movie, body)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if movie.Locale != "" {
+		w.Header().Set("Content-Language", movie.Locale)
+	}
+
+	if app.checkNotModified(w, r, etag) {
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, body, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
 	}
 }
 
@@ -94,18 +192,16 @@ func (app *application) updateMovieHandler(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
-	if expectedVersion := r.Header.Get("X-Expected-Version"); expectedVersion != "" {
-		if strconv.FormatInt(int64(movie.Version), 10) != expectedVersion {
-			app.editConflictResponse(w, r)
-			return
-		}
+	if !app.checkMoviePreconditions(w, r, movie) {
+		return
 	}
 
 	var input struct {
-		Title   string       `json:"title"`
-		Year    int          `json:"year"`
-		Runtime data.Runtime `json:"runtime"`
-		Genres  []string     `json:"genres"`
+		Title     string       `json:"title"`
+		Year      int          `json:"year"`
+		Announced bool         `json:"announced"`
+		Runtime   data.Runtime `json:"runtime"`
+		Genres    []string     `json:"genres"`
 	}
 
 	err = app.readJSON(w, r, &input)
@@ -116,16 +212,30 @@ func (app *application) updateMovieHandler(w http.ResponseWriter, r *http.Reques
 
 	movie.Title = input.Title
 	movie.Year = input.Year
+	movie.Announced = input.Announced
 	movie.Runtime = input.Runtime
 	movie.Genres = input.Genres
 
+	genres, err := app.genreRegistry()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
 	v := validator.New()
-	if data.ValidateMovie(v, *movie); !v.Valid() {
+	if data.ValidateMovie(v, movie, genres); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
-	if err := app.models.Movies.Update(movie); err != nil {
+	err = app.models.Transaction(r.Context(), func(models data.Models) error {
+		if err := models.Movies.Update(movie); err != nil {
+			return err
+		}
+
+		return app.recordMovieRevision(models, r, data.RevisionUpdate, movie)
+	})
+	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrEditConflict):
 			app.editConflictResponse(w, r)
@@ -136,7 +246,12 @@ func (app *application) updateMovieHandler(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
-	if err := app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, nil); err != nil {
+	headers := make(http.Header)
+	headers.Set("ETag", movieETag(movie))
+
+	app.setRuntimeFormat(r, movie)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, headers); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
@@ -161,46 +276,34 @@ func (app *application) partialUpdateMovieHandler(w http.ResponseWriter, r *http
 		}
 	}
 
-	if expectedVersion := r.Header.Get("X-Expected-Version"); expectedVersion != "" {
-		if strconv.FormatInt(int64(movie.Version), 10) != expectedVersion {
-			app.editConflictResponse(w, r)
-			return
-		}
+	if !app.checkMoviePreconditions(w, r, movie) {
+		return
 	}
 
-	var input struct {
-		Title   *string       `json:"title"`
-		Year    *int          `json:"year"`
-		Runtime *data.Runtime `json:"runtime"`
-		Genres  []string      `json:"genres"`
+	if !app.patchMovie(w, r, movie) {
+		return
 	}
 
-	err = app.readJSON(w, r, &input)
+	genres, err := app.genreRegistry()
 	if err != nil {
-		app.badRequestResponse(w, r, err)
+		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	if input.Title != nil {
-		movie.Title = *input.Title
-	}
-	if input.Year != nil {
-		movie.Year = *input.Year
-	}
-	if input.Runtime != nil {
-		movie.Runtime = *input.Runtime
-	}
-	if input.Genres != nil {
-		movie.Genres = input.Genres
-	}
-
 	v := validator.New()
-	if data.ValidateMovie(v, *movie); !v.Valid() {
+	if data.ValidateMovie(v, movie, genres); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
-	if err := app.models.Movies.Update(movie); err != nil {
+	err = app.models.Transaction(r.Context(), func(models data.Models) error {
+		if err := models.Movies.Update(movie); err != nil {
+			return err
+		}
+
+		return app.recordMovieRevision(models, r, data.RevisionUpdate, movie)
+	})
+	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrEditConflict):
 			app.editConflictResponse(w, r)
@@ -211,7 +314,12 @@ func (app *application) partialUpdateMovieHandler(w http.ResponseWriter, r *http
 		}
 	}
 
-	if err := app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, nil); err != nil {
+	headers := make(http.Header)
+	headers.Set("ETag", movieETag(movie))
+
+	app.setRuntimeFormat(r, movie)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, headers); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
@@ -224,7 +332,30 @@ func (app *application) deleteMovieHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if err := app.models.Movies.Delete(id); err != nil {
+	movie, err := app.models.Movies.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+			return
+		default:
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	if !app.checkMoviePreconditions(w, r, movie) {
+		return
+	}
+
+	err = app.models.Transaction(r.Context(), func(models data.Models) error {
+		if err := models.Movies.Delete(movie.ID); err != nil {
+			return err
+		}
+
+		return app.recordMovieRevision(models, r, data.RevisionDelete, movie)
+	})
+	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			app.notFoundResponse(w, r)
@@ -243,20 +374,127 @@ func (app *application) deleteMovieHandler(w http.ResponseWriter, r *http.Reques
 
 func (app *application) listMoviesHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Title  string
-		Genres []string
+		data.MovieQuery
 		data.Filters
 	}
 
+	genres, err := app.genreRegistry()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
 	v := validator.New()
 	qs := r.URL.Query()
 
-	input.Title = app.readString(qs, "title", "")
-	input.Genres = app.readCSV(qs, "genres", []string{})
+	input.MovieQuery = app.readMovieQuery(qs, genres, v)
 	input.Filters.Page = app.readInt(qs, "page", 1, v)
 	input.Filters.PageSize = app.readInt(qs, "page_size", 20, v)
 	input.Filters.Sort = app.readString(qs, "sort", "id")
-	input.Filters.SortSafeList = []string{"id", "title", "year", "runtime", "-id", "-title", "-year", "-runtime"}
+	input.Filters.SortSafeList = []string{"id", "title", "year", "runtime", "rating", "relevance", "-id", "-title", "-year", "-runtime", "-rating"}
+	input.Filters.UseCursor = qs.Has("cursor")
+	input.Filters.Cursor = app.readString(qs, "cursor", "")
+	input.Filters.CursorScope = input.MovieQuery.Fingerprint()
+
+	if input.Filters.Sort == "relevance" {
+		v.Check(input.MovieQuery.Search != "", "sort", "relevance requires the q parameter")
+		v.Check(!input.Filters.UseCursor, "sort", "relevance is not supported with cursor pagination")
+	}
+
+	data.ValidateMovieQuery(v, input.MovieQuery)
+	data.ValidateFilters(v, input.Filters)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if input.MovieQuery.CertificationMax != "" {
+		exists, err := app.models.Certifications.Exists(input.MovieQuery.CertificationCountry, input.MovieQuery.CertificationMax)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if !exists {
+			v.AddError("certification_max", "is not a known certification for certification_country")
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+	}
+
+	movies, pageInfo, err := app.models.Movies.GetAll(input.MovieQuery, input.Filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.localizeMovies(w, r, movies...); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.setRuntimeFormat(r, movies...)
+
+	output, err := app.selectFields(movies, input.MovieQuery.Fields)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	body := envelope{"movies": output, "pageInfo": pageInfo}
+
+	hash, err := hashBody(body)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if app.checkNotModified(w, r, `"`+hash+`"`) {
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, body, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// readMovieQuery reads the movie list filters. Genres are resolved through the
+// registry so that aliases match the canonical slugs stored on movies.
+func (app *application) readMovieQuery(qs url.Values, genres *data.GenreRegistry, v *validator.Validator) data.MovieQuery {
+	return data.MovieQuery{
+		Title:                app.readString(qs, "title", ""),
+		Genres:               genres.CanonicalAll(app.readCSV(qs, "genres", []string{})),
+		GenresMode:           app.readString(qs, "genres_mode", data.GenresModeAll),
+		YearMin:              app.readInt(qs, "year_min", 0, v),
+		YearMax:              app.readInt(qs, "year_max", 0, v),
+		RuntimeMin:           app.readInt(qs, "runtime_min", 0, v),
+		RuntimeMax:           app.readInt(qs, "runtime_max", 0, v),
+		PersonID:             int64(app.readInt(qs, "person_id", 0, v)),
+		CollectionID:         int64(app.readInt(qs, "collection_id", 0, v)),
+		ReleasedIn:           strings.ToUpper(app.readString(qs, "released_in", "")),
+		ReleasedBefore:       app.readDate(qs, "released_before", v),
+		CertificationCountry: strings.ToUpper(app.readString(qs, "certification_country", "")),
+		CertificationMax:     strings.ToUpper(app.readString(qs, "certification_max", "")),
+		Search:               app.readString(qs, "q", ""),
+		SearchConfig:         app.readString(qs, "search_config", "english"),
+		Highlight:            app.readBool(qs, "highlight", false, v),
+		Fields:               app.readCSV(qs, "fields", []string{}),
+	}
+}
+
+func (app *application) listTrashedMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		data.Filters
+	}
+
+	v := validator.New()
+	qs := r.URL.Query()
+
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "page_size", 20, v)
+	input.Filters.Sort = app.readString(qs, "sort", "-deleted_at")
+	input.Filters.SortSafeList = []string{"id", "title", "deleted_at", "-id", "-title", "-deleted_at"}
 
 	data.ValidateFilters(v, input.Filters)
 	if !v.Valid() {
@@ -264,14 +502,51 @@ func (app *application) listMoviesHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	movies, pageInfo, err := app.models.Movies.GetAll(input.Title, input.Genres, input.Filters)
+	movies, pageInfo, err := app.models.Movies.GetTrash(input.Filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
+	app.setRuntimeFormat(r, movies...)
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"movies": movies, "pageInfo": pageInfo}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 }
+
+func (app *application) restoreMovieHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var movie *data.Movie
+	err = app.models.Transaction(r.Context(), func(models data.Models) error {
+		movie, err = models.Movies.Restore(id)
+		if err != nil {
+			return err
+		}
+
+		return app.recordMovieRevision(models, r, data.RevisionRestore, movie)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+			return
+		default:
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	app.setRuntimeFormat(r, movie)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"greenlight.pvargasb.com/internal/data"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+func (app *application) autocompleteMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	qs := r.URL.Query()
+
+	prefix := strings.TrimSpace(app.readString(qs, "prefix", ""))
+	limit := app.readInt(qs, "limit", 10, v)
+
+	if data.ValidateAutocomplete(v, prefix, limit); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	suggestions, err := app.models.Movies.Autocomplete(prefix, limit)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"suggestions": suggestions}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
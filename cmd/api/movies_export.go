@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"greenlight.pvargasb.com/internal/data"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+
+	exportFlushEvery = 100
+)
+
+func (app *application) exportMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		data.MovieQuery
+		Format string
+	}
+
+	genres, err := app.genreRegistry()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	qs := r.URL.Query()
+
+	input.MovieQuery = app.readMovieQuery(qs, genres, v)
+	input.MovieQuery.Highlight = false
+	input.Format = app.readString(qs, "format", "")
+
+	if input.Format != "" {
+		v.Check(validator.In(input.Format, exportFormatCSV, exportFormatNDJSON), "format", "must be csv or ndjson")
+	}
+
+	data.ValidateMovieQuery(v, input.MovieQuery)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	w.Header().Add("Vary", "Accept")
+
+	if input.Format == "" {
+		input.Format = negotiateExportFormat(r.Header.Get("Accept"))
+		if input.Format == "" {
+			app.notAcceptableResponse(w, r)
+			return
+		}
+	}
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	buf := bufio.NewWriter(w)
+	var write func(*data.Movie) error
+	var flush func() error
+
+	switch input.Format {
+	case exportFormatCSV:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="movies.csv"`)
+
+		cw := csv.NewWriter(buf)
+		if err := cw.Write([]string{"id", "title", "year", "runtime", "genres", "version"}); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		write = func(movie *data.Movie) error {
+			return cw.Write([]string{
+				strconv.Itoa(movie.ID),
+				movie.Title,
+				strconv.Itoa(movie.Year),
+				strconv.Itoa(int(movie.Runtime)),
+				strings.Join(movie.Genres, "|"),
+				strconv.Itoa(movie.Version),
+			})
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	case exportFormatNDJSON:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="movies.ndjson"`)
+
+		enc := json.NewEncoder(buf)
+		write = func(movie *data.Movie) error {
+			app.setRuntimeFormat(r, movie)
+			return enc.Encode(movie)
+		}
+		flush = func() error {
+			return nil
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	count := 0
+	err = app.models.Movies.Stream(r.Context(), input.MovieQuery, func(movie *data.Movie) error {
+		if err := write(movie); err != nil {
+			return err
+		}
+
+		if count++; count%exportFlushEvery == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			if err := buf.Flush(); err != nil {
+				return err
+			}
+			if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err == nil {
+		if err = flush(); err == nil {
+			err = buf.Flush()
+		}
+	}
+	if err != nil {
+		// The status line has already been sent, so abort the connection to
+		// make the truncated body detectable by the client.
+		if r.Context().Err() == nil {
+			app.logError(r, err)
+		}
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func negotiateExportFormat(accept string) string {
+	if strings.TrimSpace(accept) == "" {
+		return exportFormatNDJSON
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+
+		switch mediaType {
+		case "text/csv", "text/*":
+			return exportFormatCSV
+		case "application/x-ndjson", "application/ndjson", "application/*", "*/*":
+			return exportFormatNDJSON
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"greenlight.pvargasb.com/internal/data"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+var errMovieInTrash = errors.New("movie is in the trash")
+
+func (app *application) lookupMovieHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	source := strings.ToLower(app.readString(qs, "source", ""))
+	externalID := app.readString(qs, "id", "")
+
+	v := validator.New()
+	if data.ValidateExternalID(v, source, externalID); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	movie, err := app.getMovieByExternalID(source, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound), errors.Is(err, errMovieInTrash):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.setRuntimeFormat(r, movie)
+
+	body := envelope{"movie": movie}
+
+	etag, err := movieRepresentationETag(movie, body)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if app.checkNotModified(w, r, etag) {
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, body, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// upsertMovieByExternalIDHandler creates the movie linked to an external ID,
+// or replaces it if it already exists. Repeating a request is a no-op: the
+// movie is only updated, and its version bumped, when something has changed.
+// Creating a movie that matches an existing title and year is refused unless
+// force=true is set; the existing movie should be linked instead.
+func (app *application) upsertMovieByExternalIDHandler(w http.ResponseWriter, r *http.Request) {
+	source := strings.ToLower(r.PathValue("source"))
+	externalID := r.PathValue("id")
+
+	v := validator.New()
+	force := app.readBool(r.URL.Query(), "force", false, v)
+	if data.ValidateExternalID(v, source, externalID); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	movie, err := app.getMovieByExternalID(source, externalID)
+	switch {
+	case errors.Is(err, data.ErrRecordNotFound):
+	case errors.Is(err, errMovieInTrash):
+		app.movieInTrashResponse(w, r)
+		return
+	case err != nil:
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	created := movie == nil
+	if created {
+		movie = &data.Movie{}
+	} else if !app.checkMoviePreconditions(w, r, movie) {
+		return
+	}
+
+	var input struct {
+		Title     string       `json:"title"`
+		Year      int          `json:"year"`
+		Announced bool         `json:"announced"`
+		Runtime   data.Runtime `json:"runtime"`
+		Genres    []string     `json:"genres"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	before := *movie
+
+	movie.Title = input.Title
+	movie.Year = input.Year
+	movie.Announced = input.Announced
+	movie.Runtime = input.Runtime
+	movie.Genres = input.Genres
+
+	genres, err := app.genreRegistry()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if data.ValidateMovie(v, movie, genres); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// A movie that is already in the catalogue should be linked to the
+	// external ID rather than created again.
+	if created && !force {
+		candidates, err := app.models.Movies.FindDuplicates(movie.Title, movie.Year, false)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if len(candidates) > 0 {
+			app.duplicateMovieResponse(w, r, candidates)
+			return
+		}
+	}
+
+	switch {
+	case created:
+		err = app.models.Transaction(r.Context(), func(models data.Models) error {
+			if err := models.Movies.Insert(movie); err != nil {
+				return err
+			}
+
+			if err := models.ExternalIDs.Insert(movie.ID, source, externalID); err != nil {
+				return err
+			}
+
+			return app.recordMovieRevision(models, r, data.RevisionInsert, movie)
+		})
+	case len(data.DiffMovies(before, *movie)) > 0:
+		err = app.models.Transaction(r.Context(), func(models data.Models) error {
+			if err := models.Movies.Update(movie); err != nil {
+				return err
+			}
+
+			return app.recordMovieRevision(models, r, data.RevisionUpdate, movie)
+		})
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict), errors.Is(err, data.ErrDuplicateExternalID):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	status := http.StatusOK
+	headers := make(http.Header)
+	headers.Set("ETag", movieETag(movie))
+
+	if created {
+		status = http.StatusCreated
+		headers.Set("Location", fmt.Sprintf("/v1/movies/%d", movie.ID))
+		movie.ExternalIDs = map[string]string{source: externalID}
+	}
+
+	app.setRuntimeFormat(r, movie)
+
+	if err := app.writeJSON(w, status, envelope{"movie": movie}, headers); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// putMovieResourceHandler serves the PUT routes with three segments after
+// /v1/movies. PUT /v1/movies/by-external/{source}/{id} overlaps with the
+// per-movie sub-resources without either being more specific, so ServeMux
+// can't register them side by side.
+func (app *application) putMovieResourceHandler(w http.ResponseWriter, r *http.Request) {
+	id, resource, key := r.PathValue("id"), r.PathValue("resource"), r.PathValue("key")
+
+	if id == "by-external" {
+		r.SetPathValue("source", resource)
+		r.SetPathValue("id", key)
+		app.upsertMovieByExternalIDHandler(w, r)
+		return
+	}
+
+	switch resource {
+	case "translations":
+		r.SetPathValue("locale", key)
+		app.putTranslationHandler(w, r)
+	case "certifications":
+		r.SetPathValue("country", key)
+		app.putMovieCertificationHandler(w, r)
+	case "external_ids":
+		r.SetPathValue("source", key)
+		app.linkExternalIDHandler(w, r)
+	default:
+		app.notFoundResponse(w, r)
+	}
+}
+
+// linkExternalIDHandler attaches an external ID to a movie that is already in
+// the catalogue, so that later upserts by that ID update it rather than
+// creating a duplicate.
+func (app *application) linkExternalIDHandler(w http.ResponseWriter, r *http.Request) {
+	movie, ok := app.readMovieParam(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		ID string `json:"id"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	source := strings.ToLower(r.PathValue("source"))
+
+	v := validator.New()
+	if data.ValidateExternalID(v, source, input.ID); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	inserted, err := app.models.ExternalIDs.Link(movie.ID, source, input.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateExternalID):
+			v.AddError("id", "is already linked to another movie")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	externalIDs, err := app.models.ExternalIDs.GetAllForMovie(movie.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if inserted {
+		status = http.StatusCreated
+	}
+
+	if err := app.writeJSON(w, status, envelope{"external_ids": externalIDs}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// getMovieByExternalID returns the movie linked to the external ID along with
+// all of its external IDs. It returns errMovieInTrash if the linked movie
+// has been deleted but not yet purged.
+func (app *application) getMovieByExternalID(source, externalID string) (*data.Movie, error) {
+	id, err := app.models.ExternalIDs.GetMovieID(source, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	movie, err := app.models.Movies.Get(id)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			return nil, errMovieInTrash
+		}
+		return nil, err
+	}
+
+	movie.ExternalIDs, err = app.models.ExternalIDs.GetAllForMovie(movie.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return movie, nil
+}
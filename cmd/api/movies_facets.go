@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+
+	"greenlight.pvargasb.com/internal/data"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+func (app *application) movieFacetsHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		data.MovieQuery
+		YearBucket    int
+		RuntimeBucket int
+	}
+
+	genres, err := app.genreRegistry()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	qs := r.URL.Query()
+
+	input.MovieQuery = app.readMovieQuery(qs, genres, v)
+	input.MovieQuery.Highlight = false
+	input.YearBucket = app.readInt(qs, "year_bucket", 10, v)
+	input.RuntimeBucket = app.readInt(qs, "runtime_bucket", 30, v)
+
+	v.Check(input.YearBucket > 0, "year_bucket", "must be greater than zero")
+	v.Check(input.YearBucket <= 100, "year_bucket", "must be a maximum of 100")
+	v.Check(input.RuntimeBucket > 0, "runtime_bucket", "must be greater than zero")
+	v.Check(input.RuntimeBucket <= 600, "runtime_bucket", "must be a maximum of 600")
+
+	data.ValidateMovieQuery(v, input.MovieQuery)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	facets, err := app.models.Movies.Facets(input.MovieQuery, input.YearBucket, input.RuntimeBucket)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"facets": facets}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"greenlight.pvargasb.com/internal/data"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+const (
+	importModeAtomic     = "atomic"
+	importModeBestEffort = "best_effort"
+
+	importMaxBytes = 32 * 1024 * 1024
+	importMaxRows  = 10_000
+)
+
+type importRow struct {
+	Row    int               `json:"row"`
+	ID     int               `json:"id,omitempty"`
+	Errors map[string]string `json:"errors,omitempty"`
+
+	movie *data.Movie
+}
+
+func (app *application) importMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	mode := app.readString(r.URL.Query(), "mode", importModeAtomic)
+	if v.Check(validator.In(mode, importModeAtomic, importModeBestEffort), "mode", "must be atomic or best_effort"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("a valid Content-Type header must be provided"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, importMaxBytes)
+
+	var rows []*importRow
+	switch contentType {
+	case "application/x-ndjson", "application/ndjson":
+		rows, err = app.readNDJSONMovies(r.Body)
+	case "text/csv":
+		rows, err = app.readCSVMovies(r.Body)
+	default:
+		app.unsupportedMediaTypeResponse(w, r, contentType)
+		return
+	}
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesError):
+			app.badRequestResponse(w, r, fmt.Errorf("body must not be larger than %d bytes", importMaxBytes))
+		default:
+			app.badRequestResponse(w, r, err)
+		}
+		return
+	}
+
+	if len(rows) == 0 {
+		app.badRequestResponse(w, r, errors.New("body must contain at least one movie"))
+		return
+	}
+
+	genres, err := app.genreRegistry()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	movies := []*data.Movie{}
+	for _, row := range rows {
+		if row.Errors != nil {
+			continue
+		}
+
+		v := validator.New()
+		if data.ValidateMovie(v, row.movie, genres); !v.Valid() {
+			row.Errors = v.Errors
+			continue
+		}
+
+		movies = append(movies, row.movie)
+	}
+
+	failed := len(rows) - len(movies)
+	if mode == importModeAtomic && failed > 0 {
+		if err := app.writeJSON(w, http.StatusUnprocessableEntity, envelope{"rows": rows, "created": 0, "failed": failed}, nil); err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	switch {
+	case len(movies) == 0:
+	case mode == importModeAtomic:
+		err := app.models.Transaction(r.Context(), func(models data.Models) error {
+			if err := models.Movies.InsertMany(movies); err != nil {
+				return err
+			}
+
+			for _, movie := range movies {
+				if err := app.recordMovieRevision(models, r, data.RevisionInsert, movie); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	default:
+		// Each row is saved on its own, so a row that the database rejects is
+		// reported without losing the others.
+		for _, row := range rows {
+			if row.Errors != nil {
+				continue
+			}
+
+			err := app.models.Transaction(r.Context(), func(models data.Models) error {
+				if err := models.Movies.Insert(row.movie); err != nil {
+					return err
+				}
+
+				return app.recordMovieRevision(models, r, data.RevisionInsert, row.movie)
+			})
+			if err != nil {
+				app.logError(r, err)
+				row.Errors = map[string]string{"movie": "could not be saved"}
+				failed++
+			}
+		}
+	}
+
+	for _, row := range rows {
+		if row.Errors == nil {
+			row.ID = row.movie.ID
+		}
+	}
+
+	status := http.StatusCreated
+	if failed > 0 {
+		status = http.StatusOK
+	}
+
+	if err := app.writeJSON(w, status, envelope{"rows": rows, "created": len(rows) - failed, "failed": failed}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) readNDJSONMovies(body io.Reader) ([]*importRow, error) {
+	var rows []*importRow
+
+	reader := bufio.NewReader(body)
+	for line := 1; ; line++ {
+		content, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		if content = bytes.TrimSpace(content); len(content) > 0 {
+			if len(rows) == importMaxRows {
+				return nil, fmt.Errorf("body must not contain more than %d movies", importMaxRows)
+			}
+
+			rows = append(rows, decodeNDJSONMovie(line, content))
+		}
+
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+	}
+}
+
+func decodeNDJSONMovie(line int, content []byte) *importRow {
+	var input struct {
+		Title     string       `json:"title"`
+		Year      int          `json:"year"`
+		Announced bool         `json:"announced"`
+		Runtime   data.Runtime `json:"runtime"`
+		Genres    []string     `json:"genres"`
+	}
+
+	row := &importRow{Row: line}
+
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&input); err != nil {
+		row.Errors = map[string]string{"row": "contains invalid JSON: " + err.Error()}
+		return row
+	}
+	if dec.More() {
+		row.Errors = map[string]string{"row": "must only contain a single JSON value"}
+		return row
+	}
+
+	row.movie = &data.Movie{
+		Title:     input.Title,
+		Year:      input.Year,
+		Announced: input.Announced,
+		Runtime:   input.Runtime,
+		Genres:    input.Genres,
+	}
+
+	return row
+}
+
+func (app *application) readCSVMovies(body io.Reader) ([]*importRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("body must not be empty")
+		}
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"title", "year", "runtime", "genres"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV header must contain a %q column", name)
+		}
+	}
+
+	var rows []*importRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+
+		if len(rows) == importMaxRows {
+			return nil, fmt.Errorf("body must not contain more than %d movies", importMaxRows)
+		}
+
+		var parseError *csv.ParseError
+		switch {
+		case errors.As(err, &parseError):
+			rows = append(rows, &importRow{Row: line, Errors: map[string]string{"row": parseError.Err.Error()}})
+			continue
+		case err != nil:
+			return nil, err
+		}
+
+		rows = append(rows, decodeCSVMovie(line, record, columns))
+	}
+}
+
+func decodeCSVMovie(line int, record []string, columns map[string]int) *importRow {
+	row := &importRow{Row: line}
+	v := validator.New()
+
+	field := func(name string) string {
+		if i := columns[name]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	movie := &data.Movie{Title: field("title")}
+
+	if year := field("year"); year != "" {
+		parsed, err := strconv.Atoi(year)
+		v.Check(err == nil, "year", "must be an integer value")
+		movie.Year = parsed
+	}
+
+	if announced := field("announced"); announced != "" {
+		parsed, err := strconv.ParseBool(announced)
+		v.Check(err == nil, "announced", "must be a boolean value")
+		movie.Announced = parsed
+	}
+
+	if runtime := field("runtime"); runtime != "" {
+		parsed, err := data.ParseRuntime(runtime)
+		v.Check(err == nil, "runtime", data.ErrInvalidRuntimeFormat.Error())
+		movie.Runtime = parsed
+	}
+
+	if genres := field("genres"); genres != "" {
+		movie.Genres = slices.DeleteFunc(strings.Split(genres, "|"), func(genre string) bool {
+			return strings.TrimSpace(genre) == ""
+		})
+		for i, genre := range movie.Genres {
+			movie.Genres[i] = strings.TrimSpace(genre)
+		}
+	}
+
+	if !v.Valid() {
+		row.Errors = v.Errors
+		return row
+	}
+
+	row.movie = movie
+	return row
+}
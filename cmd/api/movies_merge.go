@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"greenlight.pvargasb.com/internal/data"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+func (app *application) mergeMovieHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Into int `json:"into"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.Into > 0, "into", "must be provided")
+	v.Check(input.Into != id, "into", "must be a different movie")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	source, err := app.models.Movies.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !app.checkMoviePreconditions(w, r, source) {
+		return
+	}
+
+	target, err := app.models.Movies.Get(input.Into)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("into", "movie does not exist")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	genres, err := app.genreRegistry()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	merged := []string{}
+	for _, genre := range append(slices.Clone(target.Genres), source.Genres...) {
+		slug, _ := genres.Canonical(genre)
+		if !slices.Contains(merged, slug) {
+			merged = append(merged, slug)
+		}
+	}
+	target.Genres = merged
+
+	if data.ValidateMovie(v, target, genres); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	var report *data.MergeReport
+	err = app.models.Transaction(r.Context(), func(models data.Models) error {
+		report, err = models.Movies.Merge(source, target)
+		if err != nil {
+			return err
+		}
+
+		if err := app.recordMovieRevision(models, r, data.RevisionMerge, source); err != nil {
+			return err
+		}
+
+		return app.recordMovieRevision(models, r, data.RevisionUpdate, target)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	for _, prefix := range report.OrphanedImages {
+		app.deleteStoredFiles(prefix)
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/movies/%d", target.ID))
+	headers.Set("ETag", movieETag(target))
+
+	app.setRuntimeFormat(r, target)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"movie": target, "merge": report}, headers); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// redirectMergedMovie responds with a permanent redirect if the movie was
+// merged into another one. It returns false if there is no redirect.
+func (app *application) redirectMergedMovie(w http.ResponseWriter, r *http.Request, id int) bool {
+	to, err := app.models.Movies.GetRedirect(id)
+	if err != nil {
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			app.serverErrorResponse(w, r, err)
+			return true
+		}
+		return false
+	}
+
+	location := fmt.Sprintf("/v1/movies/%d", to)
+	if r.URL.RawQuery != "" {
+		location += "?" + r.URL.RawQuery
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", location)
+
+	if err := app.writeJSON(w, http.StatusMovedPermanently, envelope{"moved_to": to}, headers); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+
+	return true
+}
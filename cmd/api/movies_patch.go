@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"greenlight.pvargasb.com/internal/data"
+	"greenlight.pvargasb.com/internal/jsonpatch"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+const (
+	contentTypeJSONPatch  = "application/json-patch+json"
+	contentTypeMergePatch = "application/merge-patch+json"
+)
+
+// moviePatchDocument is the representation that JSON Patch and JSON Merge
+// Patch documents are applied to. The id and version are included so that
+// patches can test against them, but they cannot be changed.
+type moviePatchDocument struct {
+	ID        int           `json:"id"`
+	Title     *string       `json:"title"`
+	Year      *int          `json:"year"`
+	Announced bool          `json:"announced"`
+	Runtime   *data.Runtime `json:"runtime"`
+	Genres    []string      `json:"genres"`
+	Version   int           `json:"version"`
+}
+
+// patchMovie applies the request body to movie according to its Content-Type.
+// It writes an error response and returns false if the body can't be applied.
+func (app *application) patchMovie(w http.ResponseWriter, r *http.Request, movie *data.Movie) bool {
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch mediaType {
+	case "", "application/json":
+		return app.patchMovieFields(w, r, movie)
+	case contentTypeJSONPatch, contentTypeMergePatch:
+		return app.patchMovieDocument(w, r, movie, mediaType)
+	default:
+		app.unsupportedMediaTypeResponse(w, r, contentType)
+		return false
+	}
+}
+
+func (app *application) patchMovieFields(w http.ResponseWriter, r *http.Request, movie *data.Movie) bool {
+	var input struct {
+		Title     *string       `json:"title"`
+		Year      *int          `json:"year"`
+		Announced *bool         `json:"announced"`
+		Runtime   *data.Runtime `json:"runtime"`
+		Genres    []string      `json:"genres"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return false
+	}
+
+	if input.Title != nil {
+		movie.Title = *input.Title
+	}
+	if input.Year != nil {
+		movie.Year = *input.Year
+	}
+	if input.Announced != nil {
+		movie.Announced = *input.Announced
+	}
+	if input.Runtime != nil {
+		movie.Runtime = *input.Runtime
+	}
+	if input.Genres != nil {
+		movie.Genres = input.Genres
+	}
+
+	return true
+}
+
+func (app *application) patchMovieDocument(w http.ResponseWriter, r *http.Request, movie *data.Movie, mediaType string) bool {
+	doc, err := json.Marshal(moviePatchDocument{
+		ID:        movie.ID,
+		Title:     &movie.Title,
+		Year:      &movie.Year,
+		Announced: movie.Announced,
+		Runtime:   &movie.Runtime,
+		Genres:    movie.Genres,
+		Version:   movie.Version,
+	})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return false
+	}
+
+	var patched []byte
+	switch mediaType {
+	case contentTypeJSONPatch:
+		var ops []jsonpatch.Operation
+		if err := app.readJSON(w, r, &ops); err != nil {
+			app.badRequestResponse(w, r, err)
+			return false
+		}
+
+		patched, err = jsonpatch.Apply(doc, ops)
+	default:
+		var patch json.RawMessage
+		if err := app.readJSON(w, r, &patch); err != nil {
+			app.badRequestResponse(w, r, err)
+			return false
+		}
+		if !bytes.HasPrefix(bytes.TrimSpace(patch), []byte("{")) {
+			app.badRequestResponse(w, r, errors.New("body must be a JSON object"))
+			return false
+		}
+
+		patched, err = jsonpatch.MergePatch(doc, patch)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, jsonpatch.ErrTestFailed):
+			app.patchTestFailedResponse(w, r, err)
+		default:
+			app.invalidPatchResponse(w, r, err)
+		}
+		return false
+	}
+
+	var result moviePatchDocument
+	dec := json.NewDecoder(bytes.NewReader(patched))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&result); err != nil {
+		var unmarshalTypeError *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &unmarshalTypeError):
+			app.invalidPatchResponse(w, r, fmt.Errorf("patch sets incorrect JSON type for field %q", unmarshalTypeError.Field))
+		default:
+			app.invalidPatchResponse(w, r, fmt.Errorf("patch produces an invalid movie: %w", err))
+		}
+		return false
+	}
+
+	v := validator.New()
+	v.Check(result.ID == movie.ID, "id", "cannot be modified")
+	v.Check(result.Version == movie.Version, "version", "cannot be modified")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return false
+	}
+
+	movie.Title = ""
+	if result.Title != nil {
+		movie.Title = *result.Title
+	}
+	movie.Year = 0
+	if result.Year != nil {
+		movie.Year = *result.Year
+	}
+	movie.Announced = result.Announced
+	movie.Runtime = 0
+	if result.Runtime != nil {
+		movie.Runtime = *result.Runtime
+	}
+	movie.Genres = result.Genres
+
+	return true
+}
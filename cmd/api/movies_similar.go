@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"greenlight.pvargasb.com/internal/data"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+func (app *application) listSimilarMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Weights data.SimilarityWeights
+		data.Filters
+	}
+
+	v := validator.New()
+	qs := r.URL.Query()
+
+	defaults := data.DefaultSimilarityWeights
+	input.Weights.Genres = app.readFloat(qs, "genre_weight", defaults.Genres, v)
+	input.Weights.Year = app.readFloat(qs, "year_weight", defaults.Year, v)
+	input.Weights.Runtime = app.readFloat(qs, "runtime_weight", defaults.Runtime, v)
+	input.Weights.Title = app.readFloat(qs, "title_weight", defaults.Title, v)
+	input.Weights.Rating = app.readFloat(qs, "rating_weight", defaults.Rating, v)
+
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "page_size", 10, v)
+	input.Filters.Sort = "-score"
+	input.Filters.SortSafeList = []string{"-score"}
+
+	data.ValidateSimilarityWeights(v, input.Weights)
+	data.ValidateFilters(v, input.Filters)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	movie, err := app.models.Movies.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	movies, pageInfo, err := app.models.Movies.GetSimilar(movie, input.Weights, input.Filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	for _, similar := range movies {
+		app.setRuntimeFormat(r, similar.Movie)
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"movies": movies, "pageInfo": pageInfo}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"greenlight.pvargasb.com/internal/data"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+func (app *application) listReleasesHandler(w http.ResponseWriter, r *http.Request) {
+	movie, ok := app.readMovieParam(w, r)
+	if !ok {
+		return
+	}
+
+	releases, err := app.models.Releases.GetAllForMovie(movie.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"releases": releases}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) createReleaseHandler(w http.ResponseWriter, r *http.Request) {
+	movie, ok := app.readMovieParam(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Country string    `json:"country"`
+		Kind    string    `json:"kind"`
+		Date    data.Date `json:"date"`
+		Note    string    `json:"note"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	release := &data.Release{
+		MovieID: movie.ID,
+		Country: strings.ToUpper(strings.TrimSpace(input.Country)),
+		Kind:    input.Kind,
+		Date:    input.Date,
+		Note:    input.Note,
+	}
+
+	v := validator.New()
+	if data.ValidateRelease(v, release); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Releases.Insert(release); err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateRelease):
+			v.AddError("kind", "a release of this kind already exists for this country")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusCreated, envelope{"release": release}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) deleteReleaseHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	releaseID, err := strconv.ParseInt(r.PathValue("release_id"), 10, 64)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if err := app.models.Releases.Delete(id, releaseID); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"message": "release deleted"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) listCertificationsHandler(w http.ResponseWriter, r *http.Request) {
+	certifications, err := app.models.Certifications.GetAll()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"certifications": certifications}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) listMovieCertificationsHandler(w http.ResponseWriter, r *http.Request) {
+	movie, ok := app.readMovieParam(w, r)
+	if !ok {
+		return
+	}
+
+	certifications, err := app.models.Certifications.GetAllForMovie(movie.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"certifications": certifications}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) putMovieCertificationHandler(w http.ResponseWriter, r *http.Request) {
+	movie, ok := app.readMovieParam(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Certification string `json:"certification"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	certification := &data.Certification{
+		MovieID:       movie.ID,
+		Country:       strings.ToUpper(r.PathValue("country")),
+		Certification: strings.ToUpper(strings.TrimSpace(input.Certification)),
+	}
+
+	v := validator.New()
+	if data.ValidateCertification(v, certification); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	inserted, err := app.models.Certifications.Upsert(certification)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrUnknownCertification):
+			v.AddError("certification", "is not a known certification for this country")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if inserted {
+		status = http.StatusCreated
+	}
+
+	if err := app.writeJSON(w, status, envelope{"certification": certification}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) deleteMovieCertificationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if err := app.models.Certifications.Delete(id, strings.ToUpper(r.PathValue("country"))); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"message": "certification deleted"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"greenlight.pvargasb.com/internal/data"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+// recordMovieRevision records the movie's current state through models, which
+// should be bound to the transaction that wrote the movie so the two are
+// committed together.
+func (app *application) recordMovieRevision(models data.Models, r *http.Request, action string, movie *data.Movie) error {
+	revision := &data.Revision{
+		MovieID:  movie.ID,
+		Version:  movie.Version,
+		Action:   action,
+		Snapshot: *movie,
+	}
+
+	if user := app.contextGetUser(r); !user.IsAnonymous() {
+		revision.UserID = &user.ID
+	}
+
+	return models.Revisions.Insert(revision)
+}
+
+func (app *application) listMovieRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		From int
+		To   int
+		data.Filters
+	}
+
+	v := validator.New()
+	qs := r.URL.Query()
+
+	input.From = app.readInt(qs, "from", 0, v)
+	input.To = app.readInt(qs, "to", 0, v)
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "page_size", 20, v)
+	input.Filters.Sort = app.readString(qs, "sort", "-version")
+	input.Filters.SortSafeList = []string{"version", "created_at", "-version", "-created_at"}
+
+	if qs.Has("from") || qs.Has("to") {
+		v.Check(input.From > 0, "from", "must be greater than zero")
+		v.Check(input.To > 0, "to", "must be greater than zero")
+	}
+
+	data.ValidateFilters(v, input.Filters)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	revisions, pageInfo, err := app.models.Revisions.GetAllForMovie(id, input.Filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Trashed movies still have their history listed, so the movie is only
+	// looked up when it has no revisions, which is the case for movies created
+	// before revisions were recorded.
+	if len(revisions) == 0 {
+		if _, ok := app.readMovieParam(w, r); !ok {
+			return
+		}
+	}
+
+	for _, revision := range revisions {
+		app.setRuntimeFormat(r, &revision.Snapshot)
+	}
+
+	env := envelope{"revisions": revisions, "pageInfo": pageInfo}
+
+	if input.From > 0 && input.To > 0 {
+		from, err := app.models.Revisions.GetVersion(id, input.From)
+		if err != nil {
+			app.revisionLookupError(w, r, err, "from", input.From)
+			return
+		}
+
+		to, err := app.models.Revisions.GetVersion(id, input.To)
+		if err != nil {
+			app.revisionLookupError(w, r, err, "to", input.To)
+			return
+		}
+
+		env["diff"] = envelope{
+			"from":    input.From,
+			"to":      input.To,
+			"changes": data.DiffMovies(from.Snapshot, to.Snapshot),
+		}
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, env, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) revertMovieHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	version, err := app.readIDParam(r, "version")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	movie, err := app.models.Movies.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+			return
+		default:
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	if !app.checkMoviePreconditions(w, r, movie) {
+		return
+	}
+
+	revision, err := app.models.Revisions.GetVersion(id, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+			return
+		default:
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	movie.Title = revision.Snapshot.Title
+	movie.Year = revision.Snapshot.Year
+	movie.Announced = revision.Snapshot.Announced
+	movie.Runtime = revision.Snapshot.Runtime
+	movie.Genres = revision.Snapshot.Genres
+
+	genres, err := app.genreRegistry()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if data.ValidateMovie(v, movie, genres); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Transaction(r.Context(), func(models data.Models) error {
+		if err := models.Movies.Update(movie); err != nil {
+			return err
+		}
+
+		return app.recordMovieRevision(models, r, data.RevisionUpdate, movie)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+			return
+		default:
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	headers := make(http.Header)
+	headers.Set("ETag", movieETag(movie))
+
+	app.setRuntimeFormat(r, movie)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, headers); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) revisionLookupError(w http.ResponseWriter, r *http.Request, err error, key string, version int) {
+	switch {
+	case errors.Is(err, data.ErrRecordNotFound):
+		v := validator.New()
+		v.AddError(key, "version "+strconv.Itoa(version)+" does not exist for this movie")
+		app.failedValidationResponse(w, r, v.Errors)
+	default:
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -3,9 +3,20 @@ package main
 import (
 	"expvar"
 	"net/http"
+
+	"greenlight.pvargasb.com/internal/storage"
 )
 
 func (app *application) routes() http.Handler {
+	mux := app.handlers()
+
+	// Batch
+	mux.HandleFunc("POST /v1/batch", app.batchHandler(mux))
+
+	return app.metrics(app.recoverPanic(app.enableCORS(app.rateLimit(app.authenticate(app.negotiateRuntimeFormat(mux))))))
+}
+
+func (app *application) handlers() *http.ServeMux {
 	mux := http.NewServeMux()
 
 	// Health
@@ -13,21 +24,98 @@ func (app *application) routes() http.Handler {
 
 	// Movies
 	mux.HandleFunc("GET /v1/movies", app.requirePermission("movies:read", app.listMoviesHandler))
+	mux.HandleFunc("GET /v1/movies/autocomplete", app.requirePermission("movies:read", app.autocompleteMoviesHandler))
+	mux.HandleFunc("GET /v1/movies/facets", app.requirePermission("movies:read", app.movieFacetsHandler))
+	mux.HandleFunc("GET /v1/movies/export", app.requirePermission("movies:read", app.exportMoviesHandler))
+	mux.HandleFunc("GET /v1/movies/trash", app.requirePermission("movies:write", app.listTrashedMoviesHandler))
 	mux.HandleFunc("GET /v1/movies/{id}", app.requirePermission("movies:read", app.showMovieHandler))
 	mux.HandleFunc("POST /v1/movies", app.requirePermission("movies:write", app.createMovieHandler))
+	mux.HandleFunc("POST /v1/movies/import", app.requirePermission("movies:write", app.importMoviesHandler))
 	mux.HandleFunc("PUT /v1/movies/{id}", app.requirePermission("movies:write", app.updateMovieHandler))
 	mux.HandleFunc("PATCH /v1/movies/{id}", app.requirePermission("movies:write", app.partialUpdateMovieHandler))
 	mux.HandleFunc("DELETE /v1/movies/{id}", app.requirePermission("movies:write", app.deleteMovieHandler))
+	mux.HandleFunc("POST /v1/movies/{id}/restore", app.requirePermission("movies:write", app.restoreMovieHandler))
+	mux.HandleFunc("POST /v1/movies/{id}/merge", app.requirePermission("movies:write", app.mergeMovieHandler))
+	mux.HandleFunc("GET /v1/movies/{id}/similar", app.requirePermission("movies:read", app.listSimilarMoviesHandler))
+	mux.HandleFunc("GET /v1/movies/{id}/revisions", app.requirePermission("movies:read", app.listMovieRevisionsHandler))
+	mux.HandleFunc("POST /v1/movies/{id}/revisions/{version}/revert", app.requirePermission("movies:write", app.revertMovieHandler))
+
+	// External IDs
+	mux.HandleFunc("GET /v1/movies/lookup", app.requirePermission("movies:read", app.lookupMovieHandler))
+	mux.HandleFunc("PUT /v1/movies/{id}/{resource}/{key}", app.requirePermission("movies:write", app.putMovieResourceHandler))
+
+	// Images
+	mux.HandleFunc("GET /v1/movies/{id}/images", app.requirePermission("movies:read", app.listMovieImagesHandler))
+	mux.HandleFunc("PUT /v1/movies/{id}/poster", app.requirePermission("movies:write", app.updatePosterHandler))
+	mux.HandleFunc("POST /v1/movies/{id}/stills", app.requirePermission("movies:write", app.createStillHandler))
+	mux.HandleFunc("DELETE /v1/movies/{id}/images/{image_id}", app.requirePermission("movies:write", app.deleteMovieImageHandler))
+
+	// Translations
+	mux.HandleFunc("GET /v1/movies/{id}/translations", app.requirePermission("movies:read", app.listTranslationsHandler))
+	mux.HandleFunc("DELETE /v1/movies/{id}/translations/{locale}", app.requirePermission("movies:write", app.deleteTranslationHandler))
+
+	// Releases
+	mux.HandleFunc("GET /v1/movies/{id}/releases", app.requirePermission("movies:read", app.listReleasesHandler))
+	mux.HandleFunc("POST /v1/movies/{id}/releases", app.requirePermission("movies:write", app.createReleaseHandler))
+	mux.HandleFunc("DELETE /v1/movies/{id}/releases/{release_id}", app.requirePermission("movies:write", app.deleteReleaseHandler))
+
+	// Certifications
+	mux.HandleFunc("GET /v1/certifications", app.requirePermission("movies:read", app.listCertificationsHandler))
+	mux.HandleFunc("GET /v1/movies/{id}/certifications", app.requirePermission("movies:read", app.listMovieCertificationsHandler))
+	mux.HandleFunc("DELETE /v1/movies/{id}/certifications/{country}", app.requirePermission("movies:write", app.deleteMovieCertificationHandler))
+
+	// Collections
+	mux.HandleFunc("GET /v1/collections", app.requirePermission("movies:read", app.listCollectionsHandler))
+	mux.HandleFunc("POST /v1/collections", app.requirePermission("movies:write", app.createCollectionHandler))
+	mux.HandleFunc("GET /v1/collections/{id}", app.requirePermission("movies:read", app.showCollectionHandler))
+	mux.HandleFunc("PATCH /v1/collections/{id}", app.requirePermission("movies:write", app.updateCollectionHandler))
+	mux.HandleFunc("DELETE /v1/collections/{id}", app.requirePermission("movies:write", app.deleteCollectionHandler))
+
+	// Credits
+	mux.HandleFunc("POST /v1/movies/{id}/credits", app.requirePermission("movies:write", app.createCreditHandler))
+	mux.HandleFunc("DELETE /v1/movies/{id}/credits/{person_id}", app.requirePermission("movies:write", app.deleteCreditHandler))
+
+	// People
+	mux.HandleFunc("GET /v1/people", app.requirePermission("movies:read", app.listPeopleHandler))
+	mux.HandleFunc("GET /v1/people/{id}", app.requirePermission("movies:read", app.showPersonHandler))
+	mux.HandleFunc("POST /v1/people", app.requirePermission("movies:write", app.createPersonHandler))
+	mux.HandleFunc("PATCH /v1/people/{id}", app.requirePermission("movies:write", app.updatePersonHandler))
+	mux.HandleFunc("DELETE /v1/people/{id}", app.requirePermission("movies:write", app.deletePersonHandler))
+
+	// Reviews
+	mux.HandleFunc("GET /v1/movies/{id}/reviews", app.requirePermission("movies:read", app.listMovieReviewsHandler))
+	mux.HandleFunc("POST /v1/movies/{id}/reviews", app.requireActivatedUser(app.createReviewHandler))
+	mux.HandleFunc("GET /v1/reviews/{id}", app.requirePermission("movies:read", app.showReviewHandler))
+	mux.HandleFunc("PATCH /v1/reviews/{id}", app.requireActivatedUser(app.updateReviewHandler))
+	mux.HandleFunc("DELETE /v1/reviews/{id}", app.requireActivatedUser(app.deleteReviewHandler))
+
+	// Genres
+	mux.HandleFunc("GET /v1/genres", app.requirePermission("movies:read", app.listGenresHandler))
+	mux.HandleFunc("POST /v1/genres", app.requirePermission("genres:write", app.createGenreHandler))
+	mux.HandleFunc("POST /v1/genres/{slug}/aliases", app.requirePermission("genres:write", app.addGenreAliasHandler))
+	mux.HandleFunc("POST /v1/genres/{slug}/merge", app.requirePermission("genres:write", app.mergeGenreHandler))
 
 	// Users
 	mux.HandleFunc("POST /v1/users", app.registerUserHandler)
 	mux.HandleFunc("PUT /v1/users/activate", app.activateUserHandler)
 
+	// Watchlist
+	mux.HandleFunc("GET /v1/users/me/watchlist", app.requireActivatedUser(app.listWatchlistHandler))
+	mux.HandleFunc("POST /v1/users/me/watchlist", app.requireActivatedUser(app.addToWatchlistHandler))
+	mux.HandleFunc("DELETE /v1/users/me/watchlist/{id}", app.requireActivatedUser(app.removeFromWatchlistHandler))
+
 	// Tokens
 	mux.HandleFunc("POST /v1/tokens/authenticate", app.createAuthenticationTokenHandler)
 
+	// Media
+	if local, ok := app.storage.(*storage.Local); ok {
+		if prefix := local.MountPath(); prefix != "" {
+			mux.Handle("GET "+prefix+"/", http.StripPrefix(prefix, local))
+		}
+	}
+
 	// Debug
 	mux.Handle("GET /debug/vars", expvar.Handler())
 
-	return app.metrics(app.recoverPanic(app.enableCORS(app.rateLimit(app.authenticate(mux)))))
+	return mux
 }
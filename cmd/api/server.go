@@ -43,10 +43,13 @@ func (app *application) serve() error {
 			"addr": srv.Addr,
 		})
 
+		close(app.shutdown)
 		app.wg.Wait()
 		shutdownError <- nil
 	}()
 
+	app.startTrashPurger()
+
 	app.logger.Info("Starting %s server on %s", map[string]string{
 		"env":  app.config.env,
 		"addr": srv.Addr,
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"greenlight.pvargasb.com/internal/data"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+func (app *application) listTranslationsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if _, err := app.models.Movies.Get(id); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	translations, err := app.models.Translations.GetAllForMovie(id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"translations": translations}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) putTranslationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Title    string `json:"title"`
+		Synopsis string `json:"synopsis"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	translation := &data.Translation{
+		MovieID:  id,
+		Locale:   r.PathValue("locale"),
+		Title:    input.Title,
+		Synopsis: input.Synopsis,
+	}
+
+	v := validator.New()
+	if data.ValidateTranslation(v, translation); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	translation.Locale, _ = data.NormalizeLocale(translation.Locale)
+
+	if _, err := app.models.Movies.Get(id); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	inserted, err := app.models.Translations.Upsert(translation)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	headers := make(http.Header)
+	if inserted {
+		status = http.StatusCreated
+		headers.Set("Location", fmt.Sprintf("/v1/movies/%d/translations/%s", id, translation.Locale))
+	}
+
+	if err := app.writeJSON(w, status, envelope{"translation": translation}, headers); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) deleteTranslationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	locale, ok := data.NormalizeLocale(r.PathValue("locale"))
+	if !ok {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if err := app.models.Translations.Delete(id, locale); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"message": "translation deleted"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// localizeMovies translates each movie's title into the best match for the
+// request's Accept-Language header, falling back to the original title.
+func (app *application) localizeMovies(w http.ResponseWriter, r *http.Request, movies ...*data.Movie) error {
+	w.Header().Add("Vary", "Accept-Language")
+
+	locales := data.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+
+	translations := map[int][]*data.Translation{}
+	if len(locales) > 0 && len(movies) > 0 {
+		ids := make([]int, len(movies))
+		for i, movie := range movies {
+			ids[i] = movie.ID
+		}
+
+		var err error
+		translations, err = app.models.Translations.GetForMovies(ids, locales)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, movie := range movies {
+		movie.Translate(data.BestTranslation(translations[movie.ID], locales))
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"greenlight.pvargasb.com/internal/data"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+func (app *application) listWatchlistHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Watched *bool
+		data.Filters
+	}
+
+	v := validator.New()
+	qs := r.URL.Query()
+
+	if qs.Has("watched") {
+		watched := app.readBool(qs, "watched", false, v)
+		input.Watched = &watched
+	}
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "page_size", 20, v)
+	input.Filters.Sort = app.readString(qs, "sort", "-added_at")
+	input.Filters.SortSafeList = []string{"added_at", "title", "year", "-added_at", "-title", "-year"}
+
+	data.ValidateFilters(v, input.Filters)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	entries, pageInfo, err := app.models.Watchlist.GetAllForUser(user.ID, input.Watched, input.Filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	for _, entry := range entries {
+		app.setRuntimeFormat(r, entry.Movie)
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"watchlist": entries, "pageInfo": pageInfo}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) addToWatchlistHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		MovieID int     `json:"movie_id"`
+		Watched *bool   `json:"watched"`
+		Note    *string `json:"note"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.MovieID > 0, "movie_id", "must be provided")
+	if input.Note != nil {
+		v.Check(len(*input.Note) <= 1000, "note", "must not be more than 1000 bytes long")
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	movie, err := app.models.Movies.Get(input.MovieID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("movie_id", "movie does not exist")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	entry, inserted, err := app.models.Watchlist.Upsert(user.ID, movie.ID, input.Watched, input.Note)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	entry.Movie = movie
+	app.setRuntimeFormat(r, entry.Movie)
+
+	status := http.StatusOK
+	if inserted {
+		status = http.StatusCreated
+	}
+
+	if err := app.writeJSON(w, status, envelope{"entry": entry}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) removeFromWatchlistHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	if err := app.models.Watchlist.Delete(user.ID, id); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"message": "movie removed from watchlist"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
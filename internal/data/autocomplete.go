@@ -0,0 +1,60 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+type TitleSuggestion struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Year  int    `json:"year"`
+}
+
+func ValidateAutocomplete(v *validator.Validator, prefix string, limit int) {
+	v.Check(prefix != "", "prefix", "must be provided")
+	v.Check(len(prefix) <= 100, "prefix", "must not be more than 100 bytes long")
+	v.Check(limit > 0, "limit", "must be greater than zero")
+	v.Check(limit <= 20, "limit", "must be a maximum of 20")
+}
+
+// Autocomplete returns the titles that best contain prefix, tolerating typos
+// and partial words. The KNN ordering lets Postgres read the closest titles
+// straight off the trigram GiST index instead of scoring every movie.
+func (m MovieModel) Autocomplete(prefix string, limit int) ([]*TitleSuggestion, error) {
+	query := `
+        SELECT id, title, year
+        FROM movies
+        WHERE deleted_at IS NULL AND $1 <% title
+        ORDER BY $1 <<-> title, id
+        LIMIT $2
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []*TitleSuggestion{}
+	for rows.Next() {
+		var suggestion TitleSuggestion
+
+		if err := rows.Scan(&suggestion.ID, &suggestion.Title, &suggestion.Year); err != nil {
+			return nil, err
+		}
+
+		suggestions = append(suggestions, &suggestion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
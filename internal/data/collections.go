@@ -0,0 +1,395 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+var ErrUnknownMovie = errors.New("unknown movie")
+
+type Collection struct {
+	ID          int64              `json:"id"`
+	CreatedAt   time.Time          `json:"-"`
+	Title       string             `json:"title"`
+	Description string             `json:"description,omitempty"`
+	MovieCount  int                `json:"movie_count"`
+	Movies      []*CollectionEntry `json:"movies,omitempty"`
+	Version     int                `json:"version"`
+}
+
+type CollectionEntry struct {
+	Position int    `json:"position"`
+	Movie    *Movie `json:"movie"`
+}
+
+// MovieCollection describes a collection from the point of view of one of
+// its movies, with the movies either side of it.
+type MovieCollection struct {
+	ID       int64                `json:"id"`
+	Title    string               `json:"title"`
+	Position int                  `json:"position"`
+	Previous *CollectionNeighbour `json:"previous"`
+	Next     *CollectionNeighbour `json:"next"`
+}
+
+type CollectionNeighbour struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+func ValidateCollection(v *validator.Validator, collection *Collection) {
+	v.Check(collection.Title != "", "title", "must be provided")
+	v.Check(len(collection.Title) <= 500, "title", "must not be more than 500 bytes long")
+
+	v.Check(len(collection.Description) <= 10_000, "description", "must not be more than 10000 bytes long")
+}
+
+func ValidateCollectionMovies(v *validator.Validator, movieIDs []int) {
+	v.Check(len(movieIDs) <= 500, "movie_ids", "must not contain more than 500 movies")
+
+	seen := make(map[int]bool, len(movieIDs))
+	for _, id := range movieIDs {
+		v.Check(id > 0, "movie_ids", "must only contain positive integers")
+		v.Check(!seen[id], "movie_ids", "must not contain duplicate values")
+		seen[id] = true
+	}
+}
+
+type CollectionModel struct {
+	DB DBTX
+}
+
+// Insert creates the collection with the given movies, in order. It returns
+// ErrUnknownMovie if any of the movies doesn't exist.
+func (m CollectionModel) Insert(collection *Collection, movieIDs []int) error {
+	query := `
+        INSERT INTO collections (title, description)
+        VALUES ($1, $2)
+        RETURNING id, created_at, version
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return withTx(ctx, m.DB, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, query, collection.Title, collection.Description).Scan(
+			&collection.ID,
+			&collection.CreatedAt,
+			&collection.Version,
+		)
+		if err != nil {
+			return err
+		}
+
+		return setCollectionMovies(ctx, tx, collection, movieIDs)
+	})
+}
+
+func (m CollectionModel) Get(id int64) (*Collection, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+        SELECT c.id, c.created_at, c.title, c.description, c.version, (
+            SELECT count(*)
+            FROM collection_movies cm
+            INNER JOIN movies m ON m.id = cm.movie_id
+            WHERE cm.collection_id = c.id AND m.deleted_at IS NULL
+        )
+        FROM collections c
+        WHERE c.id = $1
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var collection Collection
+	if err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&collection.ID,
+		&collection.CreatedAt,
+		&collection.Title,
+		&collection.Description,
+		&collection.Version,
+		&collection.MovieCount,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &collection, nil
+}
+
+// GetMovies returns the movies in the collection in order, leaving out any
+// that are in the trash.
+func (m CollectionModel) GetMovies(id int64) ([]*CollectionEntry, error) {
+	columns := movieSelection(nil)
+	query := fmt.Sprintf(`
+        SELECT cm.position, %s
+        FROM collection_movies cm
+        INNER JOIN movies m ON m.id = cm.movie_id
+        WHERE cm.collection_id = $1 AND m.deleted_at IS NULL
+        ORDER BY cm.position
+    `, strings.Join(qualifyColumns("m", columns), ", "))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*CollectionEntry{}
+	for rows.Next() {
+		entry := CollectionEntry{Movie: &Movie{}}
+
+		dests := append([]any{&entry.Position}, movieDestinations(entry.Movie, columns)...)
+		if err := rows.Scan(dests...); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Update saves the collection. Unless movieIDs is nil the collection's movies
+// are replaced with movieIDs, in order.
+func (m CollectionModel) Update(collection *Collection, movieIDs []int) error {
+	query := `
+        UPDATE collections
+        SET title = $1, description = $2, version = version + 1
+        WHERE id = $3 AND version = $4
+        RETURNING version
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return withTx(ctx, m.DB, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			collection.Title,
+			collection.Description,
+			collection.ID,
+			collection.Version,
+		).Scan(
+			&collection.Version,
+		)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrEditConflict
+			default:
+				return err
+			}
+		}
+
+		if movieIDs == nil {
+			return nil
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM collection_movies WHERE collection_id = $1`, collection.ID); err != nil {
+			return err
+		}
+
+		return setCollectionMovies(ctx, tx, collection, movieIDs)
+	})
+}
+
+func (m CollectionModel) Delete(id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+        DELETE FROM collections WHERE id = $1
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m CollectionModel) GetAll(title string, filters Filters) ([]*Collection, PageInfo, error) {
+	query := fmt.Sprintf(`
+        SELECT count(*) OVER(), c.id, c.created_at, c.title, c.description, c.version, (
+            SELECT count(*)
+            FROM collection_movies cm
+            INNER JOIN movies m ON m.id = cm.movie_id
+            WHERE cm.collection_id = c.id AND m.deleted_at IS NULL
+        )
+        FROM collections c
+        WHERE (to_tsvector('simple', c.title) @@ plainto_tsquery('simple', $1) OR $1 = '')
+        ORDER BY c.%s %s, c.id ASC
+        LIMIT $2 OFFSET $3
+    `, filters.sortColumn(), filters.sortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, title, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	defer rows.Close()
+
+	var totalRecords int
+	collections := []*Collection{}
+	for rows.Next() {
+		var collection Collection
+
+		err := rows.Scan(
+			&totalRecords,
+			&collection.ID,
+			&collection.CreatedAt,
+			&collection.Title,
+			&collection.Description,
+			&collection.Version,
+			&collection.MovieCount,
+		)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+
+		collections = append(collections, &collection)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	pageInfo := calculatePageInfo(totalRecords, filters.Page, filters.PageSize)
+
+	return collections, pageInfo, nil
+}
+
+// GetAllForMovie returns the collections the movie belongs to, with the
+// previous and next movies in each. Movies in the trash are skipped over.
+func (m CollectionModel) GetAllForMovie(movieID int) ([]*MovieCollection, error) {
+	query := `
+        SELECT c.id, c.title, e.position, e.prev_id, e.prev_title, e.next_id, e.next_title
+        FROM (
+            SELECT cm.collection_id, cm.movie_id, cm.position,
+                lag(m.id) OVER w AS prev_id, lag(m.title) OVER w AS prev_title,
+                lead(m.id) OVER w AS next_id, lead(m.title) OVER w AS next_title
+            FROM collection_movies cm
+            INNER JOIN movies m ON m.id = cm.movie_id
+            WHERE m.deleted_at IS NULL
+              AND cm.collection_id IN (SELECT collection_id FROM collection_movies WHERE movie_id = $1)
+            WINDOW w AS (PARTITION BY cm.collection_id ORDER BY cm.position)
+        ) e
+        INNER JOIN collections c ON c.id = e.collection_id
+        WHERE e.movie_id = $1
+        ORDER BY c.title, c.id
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, movieID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := []*MovieCollection{}
+	for rows.Next() {
+		var collection MovieCollection
+		var prevID, nextID sql.NullInt64
+		var prevTitle, nextTitle sql.NullString
+
+		err := rows.Scan(
+			&collection.ID,
+			&collection.Title,
+			&collection.Position,
+			&prevID,
+			&prevTitle,
+			&nextID,
+			&nextTitle,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if prevID.Valid {
+			collection.Previous = &CollectionNeighbour{ID: int(prevID.Int64), Title: prevTitle.String}
+		}
+		if nextID.Valid {
+			collection.Next = &CollectionNeighbour{ID: int(nextID.Int64), Title: nextTitle.String}
+		}
+
+		collections = append(collections, &collection)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+func setCollectionMovies(ctx context.Context, tx *sql.Tx, collection *Collection, movieIDs []int) error {
+	query := `
+        INSERT INTO collection_movies (collection_id, movie_id, position)
+        SELECT $1, m.id, ids.position
+        FROM unnest($2::bigint[]) WITH ORDINALITY AS ids(movie_id, position)
+        INNER JOIN movies m ON m.id = ids.movie_id AND m.deleted_at IS NULL
+    `
+
+	if len(movieIDs) == 0 {
+		collection.MovieCount = 0
+		return nil
+	}
+
+	result, err := tx.ExecContext(ctx, query, collection.ID, pq.Array(movieIDs))
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if int(rowsAffected) != len(movieIDs) {
+		return ErrUnknownMovie
+	}
+	collection.MovieCount = len(movieIDs)
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+var ErrDuplicateCredit = errors.New("duplicate credit")
+
+var CreditRoleSafeList = []string{"director", "actor", "writer", "producer", "composer", "cinematographer", "editor"}
+
+type Credit struct {
+	MovieID      int    `json:"movie_id"`
+	PersonID     int64  `json:"person_id"`
+	Name         string `json:"name,omitempty"`
+	MovieTitle   string `json:"movie_title,omitempty"`
+	Role         string `json:"role"`
+	Character    string `json:"character,omitempty"`
+	BillingOrder int    `json:"billing_order"`
+}
+
+func ValidateCredit(v *validator.Validator, credit *Credit) {
+	v.Check(credit.PersonID > 0, "person_id", "must be provided")
+
+	v.Check(credit.Role != "", "role", "must be provided")
+	v.Check(validator.In(credit.Role, CreditRoleSafeList...), "role", "invalid role")
+
+	v.Check(len(credit.Character) <= 500, "character", "must not be more than 500 bytes long")
+	v.Check(credit.Character == "" || credit.Role == "actor", "character", "must only be provided for actors")
+
+	v.Check(credit.BillingOrder >= 0, "billing_order", "must not be negative")
+}
+
+type CreditModel struct {
+	DB DBTX
+}
+
+func (m CreditModel) Insert(credit *Credit) error {
+	query := `
+        INSERT INTO movie_credits (movie_id, person_id, role, character, billing_order)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(
+		ctx,
+		query,
+		credit.MovieID,
+		credit.PersonID,
+		credit.Role,
+		credit.Character,
+		credit.BillingOrder,
+	)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "movie_credits_pkey"`:
+			return ErrDuplicateCredit
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m CreditModel) Delete(movieID int, personID int64, role string) error {
+	query := `
+        DELETE FROM movie_credits
+        WHERE movie_id = $1 AND person_id = $2 AND (role = $3 OR $3 = '')
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, movieID, personID, role)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m CreditModel) GetAllForMovie(movieID int) ([]*Credit, error) {
+	query := `
+        SELECT c.movie_id, c.person_id, p.name, c.role, c.character, c.billing_order
+        FROM movie_credits c
+        INNER JOIN people p ON p.id = c.person_id
+        WHERE c.movie_id = $1
+        ORDER BY c.role, c.billing_order, p.name
+    `
+
+	return m.query(query, movieID, func(c *Credit) *string { return &c.Name })
+}
+
+func (m CreditModel) GetAllForPerson(personID int64) ([]*Credit, error) {
+	query := `
+        SELECT c.movie_id, c.person_id, mv.title, c.role, c.character, c.billing_order
+        FROM movie_credits c
+        INNER JOIN movies mv ON mv.id = c.movie_id
+        WHERE c.person_id = $1 AND mv.deleted_at IS NULL
+        ORDER BY mv.year DESC, mv.id, c.role
+    `
+
+	return m.query(query, personID, func(c *Credit) *string { return &c.MovieTitle })
+}
+
+func (m CreditModel) query(query string, id any, label func(*Credit) *string) ([]*Credit, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credits := []*Credit{}
+	for rows.Next() {
+		var credit Credit
+
+		err := rows.Scan(
+			&credit.MovieID,
+			&credit.PersonID,
+			label(&credit),
+			&credit.Role,
+			&credit.Character,
+			&credit.BillingOrder,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		credits = append(credits, &credit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return credits, nil
+}
@@ -0,0 +1,49 @@
+package data
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+const (
+	cursorNext = "next"
+	cursorPrev = "prev"
+)
+
+type cursor struct {
+	Sort      string `json:"s"`
+	Scope     string `json:"f"`
+	Value     string `json:"v"`
+	ID        int    `json:"i"`
+	Direction string `json:"d"`
+}
+
+func (c cursor) encode() string {
+	js, err := json.Marshal(c)
+	if err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(js)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	js, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(js, &c); err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	if c.ID < 1 || (c.Direction != cursorNext && c.Direction != cursorPrev) {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
@@ -0,0 +1,93 @@
+package data
+
+import (
+	"fmt"
+	"testing"
+
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := cursor{Sort: "-year", Scope: "abc", Value: "1999", ID: 42, Direction: cursorNext}
+
+	decoded, err := decodeCursor(c.encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded != c {
+		t.Fatalf("got %+v, want %+v", decoded, c)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tbl := []string{
+		"not base64!",
+		cursor{Sort: "id", Value: "1", ID: 0, Direction: cursorNext}.encode(),
+		cursor{Sort: "id", Value: "1", ID: 1, Direction: "sideways"}.encode(),
+	}
+
+	for i, test := range tbl {
+		t.Run(fmt.Sprintf("Case %d", i+1), func(t *testing.T) {
+			if _, err := decodeCursor(test); err != ErrInvalidCursor {
+				t.Fatalf("got %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestValidateFiltersCursorScope(t *testing.T) {
+	q := MovieQuery{Genres: []string{"drama", "comedy"}, GenresMode: GenresModeAll}
+	scope := q.Fingerprint()
+
+	tbl := []struct {
+		cursor cursor
+		sort   string
+		scope  string
+		valid  bool
+	}{
+		{cursor{Sort: "-year", Scope: scope, Value: "1999", ID: 1, Direction: cursorNext}, "-year", scope, true},
+		{cursor{Sort: "-year", Scope: scope, Value: "1999", ID: 1, Direction: cursorNext}, "year", scope, false},
+		{cursor{Sort: "-year", Scope: scope, Value: "1999", ID: 1, Direction: cursorNext}, "-year", MovieQuery{GenresMode: GenresModeAll}.Fingerprint(), false},
+		{cursor{Sort: "-year", Value: "1999", ID: 1, Direction: cursorNext}, "-year", scope, false},
+	}
+
+	for i, test := range tbl {
+		t.Run(fmt.Sprintf("Case %d", i+1), func(t *testing.T) {
+			v := validator.New()
+			ValidateFilters(v, Filters{
+				Page:         1,
+				PageSize:     20,
+				Sort:         test.sort,
+				SortSafeList: []string{"year", "-year"},
+				UseCursor:    true,
+				Cursor:       test.cursor.encode(),
+				CursorScope:  test.scope,
+			})
+
+			if v.Valid() != test.valid {
+				t.Fatalf("got valid %t, want %t: %v", v.Valid(), test.valid, v.Errors)
+			}
+		})
+	}
+}
+
+func TestMovieQueryFingerprint(t *testing.T) {
+	q := MovieQuery{Genres: []string{"drama", "comedy"}, GenresMode: GenresModeAll, YearMin: 1990}
+
+	same := q
+	same.Genres = []string{"comedy", "drama"}
+	same.Fields = []string{"title"}
+	same.Highlight = true
+
+	if q.Fingerprint() != same.Fingerprint() {
+		t.Fatal("fingerprint changed with genre order or response shaping")
+	}
+
+	other := q
+	other.YearMin = 2000
+
+	if q.Fingerprint() == other.Fingerprint() {
+		t.Fatal("fingerprint did not change with the filters")
+	}
+}
@@ -0,0 +1,63 @@
+package data
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+var ErrInvalidDateFormat = errors.New(`invalid date format, expected "YYYY-MM-DD"`)
+
+type Date struct {
+	time.Time
+}
+
+func ParseDate(value string) (Date, error) {
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return Date{}, ErrInvalidDateFormat
+	}
+
+	return Date{t}, nil
+}
+
+func (d Date) String() string {
+	return d.Format(dateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+func (d *Date) UnmarshalJSON(jsonValue []byte) error {
+	value, err := strconv.Unquote(string(jsonValue))
+	if err != nil {
+		return ErrInvalidDateFormat
+	}
+
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+func (d *Date) Scan(src any) error {
+	t, ok := src.(time.Time)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into Date", src)
+	}
+
+	*d = Date{t}
+	return nil
+}
+
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
@@ -0,0 +1,137 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"time"
+
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+var ErrDuplicateExternalID = errors.New("duplicate external id")
+
+var externalSourceRX = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+func ValidateExternalID(v *validator.Validator, source, externalID string) {
+	v.Check(source != "", "source", "must be provided")
+	v.Check(validator.Matches(*externalSourceRX, source), "source", "must only contain lowercase letters, digits, hyphens and underscores")
+
+	v.Check(externalID != "", "id", "must be provided")
+	v.Check(len(externalID) <= 200, "id", "must not be more than 200 bytes long")
+}
+
+type ExternalIDModel struct {
+	DB DBTX
+}
+
+func (m ExternalIDModel) Insert(movieID int, source, externalID string) error {
+	query := `
+        INSERT INTO movie_external_ids (source, external_id, movie_id)
+        VALUES ($1, $2, $3)
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, source, externalID, movieID)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "movie_external_ids_pkey"`:
+			return ErrDuplicateExternalID
+		case err.Error() == `pq: duplicate key value violates unique constraint "movie_external_ids_movie_id_source_key"`:
+			return ErrDuplicateExternalID
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Link sets the movie's ID in source, replacing any ID it already had there.
+// It reports whether the movie had no ID in source before.
+func (m ExternalIDModel) Link(movieID int, source, externalID string) (bool, error) {
+	query := `
+        INSERT INTO movie_external_ids (source, external_id, movie_id)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (movie_id, source) DO UPDATE
+        SET external_id = EXCLUDED.external_id
+        RETURNING xmax = 0
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var inserted bool
+	if err := m.DB.QueryRowContext(ctx, query, source, externalID, movieID).Scan(&inserted); err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "movie_external_ids_pkey"`:
+			return false, ErrDuplicateExternalID
+		default:
+			return false, err
+		}
+	}
+
+	return inserted, nil
+}
+
+// GetMovieID returns the ID of the movie linked to the external ID, including
+// movies that are in the trash.
+func (m ExternalIDModel) GetMovieID(source, externalID string) (int, error) {
+	query := `
+        SELECT movie_id
+        FROM movie_external_ids
+        WHERE source = $1 AND external_id = $2
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var movieID int
+	if err := m.DB.QueryRowContext(ctx, query, source, externalID).Scan(&movieID); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrRecordNotFound
+		default:
+			return 0, err
+		}
+	}
+
+	return movieID, nil
+}
+
+func (m ExternalIDModel) GetAllForMovie(movieID int) (map[string]string, error) {
+	query := `
+        SELECT source, external_id
+        FROM movie_external_ids
+        WHERE movie_id = $1
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, movieID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]string)
+	for rows.Next() {
+		var source, externalID string
+
+		if err := rows.Scan(&source, &externalID); err != nil {
+			return nil, err
+		}
+
+		ids[source] = externalID
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,97 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type GenreFacet struct {
+	Genre string `json:"genre"`
+	Count int    `json:"count"`
+}
+
+type BucketFacet struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Count int `json:"count"`
+}
+
+type MovieFacets struct {
+	Total    int           `json:"total"`
+	Genres   []GenreFacet  `json:"genres"`
+	Years    []BucketFacet `json:"years"`
+	Runtimes []BucketFacet `json:"runtimes"`
+}
+
+func (m MovieModel) Facets(q MovieQuery, yearBucket, runtimeBucket int) (*MovieFacets, error) {
+	b := q.build()
+	yearWidth, runtimeWidth := b.args.add(yearBucket), b.args.add(runtimeBucket)
+
+	query := fmt.Sprintf(`
+        WITH filtered AS (
+            SELECT genres, year, runtime
+            FROM movies
+            WHERE %[1]s
+        )
+        SELECT 'total', '', 0, count(*) FROM filtered
+        UNION ALL
+        SELECT 'genre', genre, 0, count(*) FROM filtered, unnest(genres) AS genre GROUP BY genre
+        UNION ALL
+        SELECT 'year', '', (year / %[2]s) * %[2]s, count(*) FROM filtered GROUP BY 3
+        UNION ALL
+        SELECT 'runtime', '', (runtime / %[3]s) * %[3]s, count(*) FROM filtered GROUP BY 3
+        ORDER BY 1, 4 DESC, 2, 3
+    `, strings.Join(b.conditions, " AND "), yearWidth, runtimeWidth)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	facets := &MovieFacets{
+		Genres:   []GenreFacet{},
+		Years:    []BucketFacet{},
+		Runtimes: []BucketFacet{},
+	}
+	for rows.Next() {
+		var kind, genre string
+		var bucket, count int
+
+		if err := rows.Scan(&kind, &genre, &bucket, &count); err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case "total":
+			facets.Total = count
+		case "genre":
+			facets.Genres = append(facets.Genres, GenreFacet{Genre: genre, Count: count})
+		case "year":
+			facets.Years = append(facets.Years, BucketFacet{From: bucket, To: bucket + yearBucket - 1, Count: count})
+		case "runtime":
+			facets.Runtimes = append(facets.Runtimes, BucketFacet{From: bucket, To: bucket + runtimeBucket - 1, Count: count})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sortBuckets(facets.Years)
+	sortBuckets(facets.Runtimes)
+
+	return facets, nil
+}
+
+func sortBuckets(buckets []BucketFacet) {
+	slices.SortFunc(buckets, func(a, b BucketFacet) int {
+		return a.From - b.From
+	})
+}
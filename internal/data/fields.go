@@ -0,0 +1,79 @@
+package data
+
+import (
+	"slices"
+
+	"github.com/lib/pq"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+var MovieFieldSafeList = []string{"id", "title", "original_title", "locale", "synopsis", "year", "announced", "runtime", "genres", "version", "rating", "rating_count", "poster", "external_ids", "highlight"}
+
+var movieColumns = []struct {
+	name string
+	dest func(*Movie) any
+}{
+	{"id", func(m *Movie) any { return &m.ID }},
+	{"created_at", func(m *Movie) any { return &m.CreatedAt }},
+	{"title", func(m *Movie) any { return &m.Title }},
+	{"year", func(m *Movie) any { return &m.Year }},
+	{"announced", func(m *Movie) any { return &m.Announced }},
+	{"runtime", func(m *Movie) any { return &m.Runtime }},
+	{"genres", func(m *Movie) any { return pq.Array(&m.Genres) }},
+	{"version", func(m *Movie) any { return &m.Version }},
+	{"rating", func(m *Movie) any { return &m.Rating }},
+	{"rating_count", func(m *Movie) any { return &m.RatingCount }},
+	{"poster", func(m *Movie) any { return nullImage{&m.Poster} }},
+}
+
+func ValidateFields(v *validator.Validator, fields []string, safeList []string) {
+	for _, field := range fields {
+		if !validator.In(field, safeList...) {
+			v.AddError("fields", "invalid field: "+field)
+			return
+		}
+	}
+
+	v.Check(validator.Unique(fields), "fields", "must not contain duplicate values")
+}
+
+// movieSelection returns the movie columns needed to serve the requested
+// fields. The id and version are always selected since ETags and cursors are
+// derived from them.
+func movieSelection(fields []string, required ...string) []string {
+	var columns []string
+
+	for _, column := range movieColumns {
+		if len(fields) == 0 ||
+			column.name == "id" ||
+			column.name == "version" ||
+			slices.Contains(fields, column.name) ||
+			(column.name == "title" && slices.Contains(fields, "original_title")) ||
+			slices.Contains(required, column.name) {
+			columns = append(columns, column.name)
+		}
+	}
+
+	return columns
+}
+
+func movieDestinations(movie *Movie, columns []string) []any {
+	var dests []any
+
+	for _, column := range movieColumns {
+		if slices.Contains(columns, column.name) {
+			dests = append(dests, column.dest(movie))
+		}
+	}
+
+	return dests
+}
+
+func qualifyColumns(table string, columns []string) []string {
+	qualified := make([]string, len(columns))
+	for i, column := range columns {
+		qualified[i] = table + "." + column
+	}
+
+	return qualified
+}
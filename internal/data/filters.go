@@ -13,14 +13,19 @@ type Filters struct {
 	PageSize     int
 	Sort         string
 	SortSafeList []string
+	UseCursor    bool
+	Cursor       string
+	CursorScope  string
 }
 
 type PageInfo struct {
-	CurrentPage  int `json:"current_page,omitempty"`
-	PageSize     int `json:"page_size,omitempty"`
-	FirstPage    int `json:"first_page,omitempty"`
-	LastPage     int `json:"last_page,omitempty"`
-	TotalRecords int `json:"total_records,omitempty"`
+	CurrentPage  int    `json:"current_page,omitempty"`
+	PageSize     int    `json:"page_size,omitempty"`
+	FirstPage    int    `json:"first_page,omitempty"`
+	LastPage     int    `json:"last_page,omitempty"`
+	TotalRecords int    `json:"total_records,omitempty"`
+	NextCursor   string `json:"next_cursor,omitempty"`
+	PrevCursor   string `json:"prev_cursor,omitempty"`
 }
 
 func ValidateFilters(v *validator.Validator, f Filters) {
@@ -30,6 +35,17 @@ func ValidateFilters(v *validator.Validator, f Filters) {
 	v.Check(f.PageSize <= 100, "page_size", "must be a maximum of 100")
 
 	v.Check(validator.In(f.Sort, f.SortSafeList...), "sort", "invalid sort value")
+
+	if f.UseCursor && f.Cursor != "" {
+		c, err := decodeCursor(f.Cursor)
+		if err != nil {
+			v.AddError("cursor", "must be a valid cursor")
+			return
+		}
+
+		v.Check(c.Sort == f.Sort, "cursor", "was issued for a different sort value")
+		v.Check(c.Scope == f.CursorScope, "cursor", "was issued for different filters")
+	}
 }
 
 func (f Filters) sortColumn() string {
@@ -48,6 +64,14 @@ func (f Filters) sortDirection() string {
 	return "ASC"
 }
 
+func (f Filters) reverseSortDirection() string {
+	if f.sortDirection() == "ASC" {
+		return "DESC"
+	}
+
+	return "ASC"
+}
+
 func (f Filters) limit() int {
 	return f.PageSize
 }
@@ -0,0 +1,340 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+var (
+	ErrDuplicateGenre      = errors.New("duplicate genre")
+	ErrDuplicateGenreAlias = errors.New("duplicate genre alias")
+)
+
+var slugSeparatorRX = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+func Slugify(s string) string {
+	return strings.Trim(slugSeparatorRX.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+type Genre struct {
+	ID         int64     `json:"-"`
+	CreatedAt  time.Time `json:"-"`
+	Slug       string    `json:"slug"`
+	Name       string    `json:"name"`
+	Aliases    []string  `json:"aliases"`
+	MovieCount int       `json:"movie_count"`
+}
+
+func ValidateGenre(v *validator.Validator, genre *Genre) {
+	v.Check(genre.Name != "", "name", "must be provided")
+	v.Check(len(genre.Name) <= 100, "name", "must not be more than 100 bytes long")
+
+	v.Check(genre.Slug != "", "slug", "must be provided")
+	v.Check(genre.Slug == Slugify(genre.Slug), "slug", "must only contain lowercase letters, digits and dashes")
+
+	for _, alias := range genre.Aliases {
+		v.Check(alias != "", "aliases", "must not contain empty values")
+	}
+	v.Check(validator.Unique(genre.Aliases), "aliases", "must not contain duplicate values")
+}
+
+// GenreRegistry resolves genre names and aliases to their canonical slug.
+type GenreRegistry struct {
+	Strict bool
+	lookup map[string]string
+}
+
+func NewGenreRegistry(strict bool) *GenreRegistry {
+	return &GenreRegistry{Strict: strict, lookup: make(map[string]string)}
+}
+
+func (r *GenreRegistry) Add(slug string, aliases ...string) {
+	r.lookup[slug] = slug
+	for _, alias := range aliases {
+		if _, exists := r.lookup[Slugify(alias)]; !exists {
+			r.lookup[Slugify(alias)] = slug
+		}
+	}
+}
+
+func (r *GenreRegistry) Canonical(name string) (string, bool) {
+	key := Slugify(name)
+	if slug, ok := r.lookup[key]; ok {
+		return slug, true
+	}
+
+	return key, false
+}
+
+// CanonicalAll resolves each name to its canonical slug, dropping empty and
+// repeated values.
+func (r *GenreRegistry) CanonicalAll(names []string) []string {
+	slugs := []string{}
+	for _, name := range names {
+		slug, _ := r.Canonical(name)
+		if slug != "" && !slices.Contains(slugs, slug) {
+			slugs = append(slugs, slug)
+		}
+	}
+
+	return slugs
+}
+
+type GenreModel struct {
+	DB DBTX
+}
+
+func (m GenreModel) Registry(strict bool) (*GenreRegistry, error) {
+	query := `
+        SELECT g.slug, a.alias
+        FROM genres g
+        LEFT JOIN genre_aliases a ON a.genre_id = g.id
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slugs := make(map[string][]string)
+	for rows.Next() {
+		var slug string
+		var alias sql.NullString
+
+		if err := rows.Scan(&slug, &alias); err != nil {
+			return nil, err
+		}
+
+		if alias.Valid {
+			slugs[slug] = append(slugs[slug], alias.String)
+		} else if _, ok := slugs[slug]; !ok {
+			slugs[slug] = nil
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	registry := NewGenreRegistry(strict)
+	for slug := range slugs {
+		registry.Add(slug)
+	}
+	for slug, aliases := range slugs {
+		registry.Add(slug, aliases...)
+	}
+
+	return registry, nil
+}
+
+func (m GenreModel) Insert(genre *Genre) error {
+	query := `
+        INSERT INTO genres (slug, name)
+        VALUES ($1, $2)
+        RETURNING id, created_at
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return withTx(ctx, m.DB, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, query, genre.Slug, genre.Name).Scan(&genre.ID, &genre.CreatedAt); err != nil {
+			switch {
+			case err.Error() == `pq: duplicate key value violates unique constraint "genres_slug_key"`:
+				return ErrDuplicateGenre
+			default:
+				return err
+			}
+		}
+
+		for i, alias := range genre.Aliases {
+			genre.Aliases[i] = Slugify(alias)
+			if err := insertGenreAlias(ctx, tx, genre.ID, genre.Aliases[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (m GenreModel) AddAlias(genre *Genre, alias string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	alias = Slugify(alias)
+	if err := insertGenreAlias(ctx, m.DB, genre.ID, alias); err != nil {
+		return err
+	}
+
+	genre.Aliases = append(genre.Aliases, alias)
+
+	return nil
+}
+
+func insertGenreAlias(ctx context.Context, db DBTX, genreID int64, alias string) error {
+	query := `
+        INSERT INTO genre_aliases (alias, genre_id)
+        VALUES ($1, $2)
+    `
+
+	if _, err := db.ExecContext(ctx, query, alias, genreID); err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "genre_aliases_pkey"`:
+			return ErrDuplicateGenreAlias
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m GenreModel) Get(slug string) (*Genre, error) {
+	query := `
+        SELECT g.id, g.created_at, g.slug, g.name,
+            ARRAY(SELECT a.alias FROM genre_aliases a WHERE a.genre_id = g.id ORDER BY a.alias),
+            (SELECT count(*) FROM movies m WHERE g.slug = ANY(m.genres) AND m.deleted_at IS NULL)
+        FROM genres g
+        WHERE g.slug = $1
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var genre Genre
+	if err := m.DB.QueryRowContext(ctx, query, slug).Scan(
+		&genre.ID,
+		&genre.CreatedAt,
+		&genre.Slug,
+		&genre.Name,
+		pq.Array(&genre.Aliases),
+		&genre.MovieCount,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &genre, nil
+}
+
+func (m GenreModel) GetAll() ([]*Genre, error) {
+	query := `
+        SELECT g.id, g.created_at, g.slug, g.name,
+            ARRAY(SELECT a.alias FROM genre_aliases a WHERE a.genre_id = g.id ORDER BY a.alias),
+            count(m.id)
+        FROM genres g
+        LEFT JOIN movies m ON g.slug = ANY(m.genres) AND m.deleted_at IS NULL
+        GROUP BY g.id
+        ORDER BY count(m.id) DESC, g.slug ASC
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	genres := []*Genre{}
+	for rows.Next() {
+		var genre Genre
+
+		err := rows.Scan(
+			&genre.ID,
+			&genre.CreatedAt,
+			&genre.Slug,
+			&genre.Name,
+			pq.Array(&genre.Aliases),
+			&genre.MovieCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		genres = append(genres, &genre)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return genres, nil
+}
+
+// Merge folds source into target: movies tagged with source are rewritten to
+// target, source and its aliases become aliases of target, and source is
+// removed. It returns the rewritten movies so that callers can record their
+// revisions in the same transaction.
+func (m GenreModel) Merge(source, target *Genre) ([]*Movie, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	columns := movieSelection(nil)
+	movies := []*Movie{}
+	err := withTx(ctx, m.DB, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+            UPDATE movies
+            SET genres = CASE
+                    WHEN $2 = ANY(genres) THEN array_remove(genres, $1)
+                    ELSE array_replace(genres, $1, $2)
+                END,
+                version = version + 1
+            WHERE $1 = ANY(genres)
+            RETURNING %s
+        `, strings.Join(columns, ", ")), source.Slug, target.Slug)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var movie Movie
+			if err := rows.Scan(movieDestinations(&movie, columns)...); err != nil {
+				return err
+			}
+			movies = append(movies, &movie)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE genre_aliases SET genre_id = $2 WHERE genre_id = $1
+        `, source.ID, target.ID); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+            DELETE FROM genres WHERE id = $1
+        `, source.ID); err != nil {
+			return err
+		}
+
+		return insertGenreAlias(ctx, tx, target.ID, source.Slug)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return movies, nil
+}
@@ -0,0 +1,57 @@
+package data
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tbl := []struct {
+		input  string
+		expect string
+	}{
+		{input: "Science Fiction", expect: "science-fiction"},
+		{input: "  Sci-Fi!! ", expect: "sci-fi"},
+		{input: "Ciencia Ficción", expect: "ciencia-ficción"},
+		{input: "ドラマ", expect: "ドラマ"},
+		{input: "Film Noir (1940s)", expect: "film-noir-1940s"},
+		{input: "---", expect: ""},
+	}
+
+	for i, test := range tbl {
+		t.Run(fmt.Sprintf("Case %d", i+1), func(t *testing.T) {
+			if got := Slugify(test.input); got != test.expect {
+				t.Fatalf("got %q, want %q", got, test.expect)
+			}
+		})
+	}
+}
+
+func TestGenreRegistryCanonical(t *testing.T) {
+	registry := NewGenreRegistry(true)
+	registry.Add("science-fiction", "sci-fi", "SF")
+	registry.Add("drama")
+	registry.Add("sf")
+
+	tbl := []struct {
+		input  string
+		expect string
+		known  bool
+	}{
+		{input: "Science Fiction", expect: "science-fiction", known: true},
+		{input: "Sci-Fi", expect: "science-fiction", known: true},
+		{input: "sci fi", expect: "science-fiction", known: true},
+		{input: "  DRAMA ", expect: "drama", known: true},
+		{input: "sf", expect: "sf", known: true},
+		{input: "Film Noir", expect: "film-noir", known: false},
+	}
+
+	for i, test := range tbl {
+		t.Run(fmt.Sprintf("Case %d", i+1), func(t *testing.T) {
+			got, known := registry.Canonical(test.input)
+			if got != test.expect || known != test.known {
+				t.Fatalf("got (%q, %t), want (%q, %t)", got, known, test.expect, test.known)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+const (
+	ImageKindPoster = "poster"
+	ImageKindStill  = "still"
+)
+
+var ImageContentTypeSafeList = []string{"image/jpeg", "image/png", "image/webp"}
+
+type Image struct {
+	ID          int64             `json:"id"`
+	CreatedAt   time.Time         `json:"-"`
+	MovieID     int               `json:"-"`
+	Kind        string            `json:"kind"`
+	ContentType string            `json:"content_type"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	URL         string            `json:"url"`
+	Thumbnails  map[string]string `json:"thumbnails"`
+	Prefix      string            `json:"-"`
+}
+
+func ValidateImage(v *validator.Validator, image *Image) {
+	v.Check(validator.In(image.ContentType, ImageContentTypeSafeList...), "image", "must be a JPEG, PNG or WebP image")
+
+	v.Check(image.Width >= 100 && image.Height >= 100, "image", "must be at least 100x100 pixels")
+	v.Check(image.Width <= 8000 && image.Height <= 8000, "image", "must not be larger than 8000x8000 pixels")
+
+	if image.Kind == ImageKindPoster {
+		v.Check(image.Height >= image.Width, "image", "poster must be in portrait orientation")
+	}
+}
+
+// nullImage scans the movies.poster jsonb column, which is NULL for movies
+// without a poster.
+type nullImage struct {
+	image **Image
+}
+
+func (n nullImage) Scan(src any) error {
+	if src == nil {
+		*n.image = nil
+		return nil
+	}
+
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into image", src)
+	}
+
+	var image Image
+	if err := json.Unmarshal(b, &image); err != nil {
+		return err
+	}
+	*n.image = &image
+
+	return nil
+}
+
+type ImageModel struct {
+	DB DBTX
+}
+
+func (m ImageModel) Insert(image *Image) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return insertImage(ctx, m.DB, image)
+}
+
+// ReplacePoster stores image as the movie's poster and returns the storage
+// prefix of the poster it replaced, if any.
+func (m ImageModel) ReplacePoster(movie *Movie, image *Image) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var previous string
+	err := withTx(ctx, m.DB, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+            DELETE FROM movie_images
+            WHERE movie_id = $1 AND kind = 'poster'
+            RETURNING storage_prefix
+        `, movie.ID).Scan(&previous)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		if err := insertImage(ctx, tx, image); err != nil {
+			return err
+		}
+
+		return setPoster(ctx, tx, movie, image)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return previous, nil
+}
+
+func (m ImageModel) Delete(movie *Movie, id int64) (*Image, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	image := Image{ID: id, MovieID: movie.ID}
+	err := withTx(ctx, m.DB, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, `
+            DELETE FROM movie_images
+            WHERE id = $1 AND movie_id = $2
+            RETURNING kind, storage_prefix
+        `, id, movie.ID).Scan(&image.Kind, &image.Prefix); err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrRecordNotFound
+			default:
+				return err
+			}
+		}
+
+		if image.Kind == ImageKindPoster {
+			return setPoster(ctx, tx, movie, nil)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &image, nil
+}
+
+func (m ImageModel) GetAllForMovie(movieID int) ([]*Image, error) {
+	query := `
+        SELECT id, created_at, movie_id, kind, content_type, width, height, storage_prefix, url, thumbnails
+        FROM movie_images
+        WHERE movie_id = $1
+        ORDER BY kind, id
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, movieID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	images := []*Image{}
+	for rows.Next() {
+		var image Image
+		var thumbnails []byte
+
+		err := rows.Scan(
+			&image.ID,
+			&image.CreatedAt,
+			&image.MovieID,
+			&image.Kind,
+			&image.ContentType,
+			&image.Width,
+			&image.Height,
+			&image.Prefix,
+			&image.URL,
+			&thumbnails,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(thumbnails, &image.Thumbnails); err != nil {
+			return nil, err
+		}
+
+		images = append(images, &image)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+func insertImage(ctx context.Context, db DBTX, image *Image) error {
+	query := `
+        INSERT INTO movie_images (movie_id, kind, content_type, width, height, storage_prefix, url, thumbnails)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id, created_at
+    `
+
+	thumbnails, err := json.Marshal(image.Thumbnails)
+	if err != nil {
+		return err
+	}
+
+	return db.QueryRowContext(
+		ctx,
+		query,
+		image.MovieID,
+		image.Kind,
+		image.ContentType,
+		image.Width,
+		image.Height,
+		image.Prefix,
+		image.URL,
+		string(thumbnails),
+	).Scan(
+		&image.ID,
+		&image.CreatedAt,
+	)
+}
+
+func setPoster(ctx context.Context, tx *sql.Tx, movie *Movie, image *Image) error {
+	query := `
+        UPDATE movies
+        SET poster = $1, version = version + 1
+        WHERE id = $2 AND version = $3 AND deleted_at IS NULL
+        RETURNING version
+    `
+
+	var poster any
+	if image != nil {
+		b, err := json.Marshal(image)
+		if err != nil {
+			return err
+		}
+		poster = string(b)
+	}
+
+	if err := tx.QueryRowContext(ctx, query, poster, movie.ID, movie.Version).Scan(&movie.Version); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	movie.Poster = image
+
+	return nil
+}
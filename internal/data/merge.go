@@ -0,0 +1,253 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// FindDuplicates returns the IDs of movies with the same normalised title and
+// year. With fuzzy set it also returns movies released within a year whose
+// titles are similar.
+func (m MovieModel) FindDuplicates(title string, year int, fuzzy bool) ([]int, error) {
+	query := `
+        SELECT id
+        FROM movies
+        WHERE deleted_at IS NULL AND (
+            (normalize_title(title) = normalize_title($1) AND year = $2)
+            OR ($3 AND year BETWEEN $2 - 1 AND $2 + 1 AND similarity(title, $1) >= 0.6)
+        )
+        ORDER BY id
+        LIMIT 10
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, title, year, fuzzy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// GetRedirect returns the ID of the movie that the given movie was merged
+// into.
+func (m MovieModel) GetRedirect(id int) (int, error) {
+	query := `
+        SELECT to_id
+        FROM movie_redirects
+        WHERE from_id = $1
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var to int
+	if err := m.DB.QueryRowContext(ctx, query, id).Scan(&to); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrRecordNotFound
+		default:
+			return 0, err
+		}
+	}
+
+	return to, nil
+}
+
+// MergeReport describes what Merge could not move onto the target.
+type MergeReport struct {
+	// UnmovedReviews lists reviews left on the source because their authors
+	// had already reviewed the target.
+	UnmovedReviews []int64 `json:"unmoved_reviews"`
+	// MergedWatchlistEntries counts users who had both movies on their
+	// watchlist. Their source entry is folded into the target one.
+	MergedWatchlistEntries int `json:"merged_watchlist_entries"`
+	// OrphanedImages holds the storage prefixes of source images that were
+	// not moved.
+	OrphanedImages []string `json:"-"`
+}
+
+// Merge folds source into target. Rows that belong to the source are moved to
+// the target unless the target already has an equivalent row, and a redirect
+// is left in the source's place. The source itself is kept in the trash,
+// marked as merged, so that its revisions and anything that couldn't be moved
+// are preserved. The target is saved with its current fields, so callers set
+// the merged genres on it beforehand. Both movies' versions are bumped.
+func (m MovieModel) Merge(source, target *Movie) (*MergeReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	report := MergeReport{UnmovedReviews: []int64{}}
+	err := withTx(ctx, m.DB, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+            SELECT id FROM reviews
+            WHERE movie_id = $1 AND user_id IN (SELECT user_id FROM reviews WHERE movie_id = $2)
+            ORDER BY id
+        `, source.ID, target.ID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			report.UnmovedReviews = append(report.UnmovedReviews, id)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		// Soft deleting the source clears its watchlist entries, so fold them
+		// into the target's entries first.
+		result, err := tx.ExecContext(ctx, `
+            UPDATE watchlist_entries t
+            SET watched = t.watched OR s.watched,
+                note = CASE WHEN t.note = '' THEN s.note ELSE t.note END
+            FROM watchlist_entries s
+            WHERE s.movie_id = $1 AND t.movie_id = $2 AND s.user_id = t.user_id
+        `, source.ID, target.ID)
+		if err != nil {
+			return err
+		}
+
+		merged, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		report.MergedWatchlistEntries = int(merged)
+
+		statements := []string{
+			`INSERT INTO movie_credits (movie_id, person_id, role, character, billing_order)
+             SELECT $2, person_id, role, character, billing_order FROM movie_credits WHERE movie_id = $1
+             ON CONFLICT DO NOTHING`,
+			`UPDATE reviews SET movie_id = $2
+             WHERE movie_id = $1 AND user_id NOT IN (SELECT user_id FROM reviews WHERE movie_id = $2)`,
+			`UPDATE watchlist_entries SET movie_id = $2
+             WHERE movie_id = $1 AND user_id NOT IN (SELECT user_id FROM watchlist_entries WHERE movie_id = $2)`,
+			`INSERT INTO movie_translations (movie_id, locale, created_at, title, synopsis)
+             SELECT $2, locale, created_at, title, synopsis FROM movie_translations WHERE movie_id = $1
+             ON CONFLICT DO NOTHING`,
+			`UPDATE movie_external_ids SET movie_id = $2
+             WHERE movie_id = $1 AND source NOT IN (SELECT source FROM movie_external_ids WHERE movie_id = $2)`,
+			`UPDATE movie_images SET movie_id = $2
+             WHERE movie_id = $1 AND (kind = 'still' OR NOT EXISTS (
+                 SELECT 1 FROM movie_images WHERE movie_id = $2 AND kind = 'poster'
+             ))`,
+			`UPDATE movie_releases SET movie_id = $2
+             WHERE movie_id = $1 AND (country, kind) NOT IN (SELECT country, kind FROM movie_releases WHERE movie_id = $2)`,
+			`INSERT INTO movie_certifications (movie_id, country, certification)
+             SELECT $2, country, certification FROM movie_certifications WHERE movie_id = $1
+             ON CONFLICT DO NOTHING`,
+			`UPDATE collection_movies SET movie_id = $2
+             WHERE movie_id = $1 AND collection_id NOT IN (SELECT collection_id FROM collection_movies WHERE movie_id = $2)`,
+			`UPDATE movie_redirects SET to_id = $2 WHERE to_id = $1`,
+			`UPDATE movies SET merged_into = $2 WHERE merged_into = $1`,
+		}
+
+		for _, statement := range statements {
+			if _, err := tx.ExecContext(ctx, statement, source.ID, target.ID); err != nil {
+				return err
+			}
+		}
+
+		err = tx.QueryRowContext(ctx, `
+            UPDATE movies
+            SET genres = $1,
+                poster = COALESCE(poster, (SELECT poster FROM movies WHERE id = $2)),
+                version = version + 1
+            WHERE id = $3 AND version = $4 AND deleted_at IS NULL
+            RETURNING version, poster, rating, rating_count
+        `, pq.Array(target.Genres), source.ID, target.ID, target.Version).Scan(
+			&target.Version,
+			nullImage{&target.Poster},
+			&target.Rating,
+			&target.RatingCount,
+		)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrEditConflict
+			default:
+				return err
+			}
+		}
+
+		images, err := tx.QueryContext(ctx, `
+            DELETE FROM movie_images
+            WHERE movie_id = $1
+            RETURNING storage_prefix
+        `, source.ID)
+		if err != nil {
+			return err
+		}
+		defer images.Close()
+
+		for images.Next() {
+			var prefix string
+			if err := images.Scan(&prefix); err != nil {
+				return err
+			}
+			report.OrphanedImages = append(report.OrphanedImages, prefix)
+		}
+		if err := images.Err(); err != nil {
+			return err
+		}
+
+		err = tx.QueryRowContext(ctx, `
+            UPDATE movies
+            SET deleted_at = NOW(), merged_into = $3, poster = NULL, version = version + 1
+            WHERE id = $1 AND version = $2 AND deleted_at IS NULL
+            RETURNING version, rating, rating_count
+        `, source.ID, source.Version, target.ID).Scan(
+			&source.Version,
+			&source.Rating,
+			&source.RatingCount,
+		)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrEditConflict
+			default:
+				return err
+			}
+		}
+		source.Poster = nil
+
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO movie_redirects (from_id, to_id)
+            VALUES ($1, $2)
+        `, source.ID, target.ID)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
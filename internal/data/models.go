@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 )
@@ -10,18 +11,101 @@ var (
 	ErrEditConflict   = errors.New("edit conflict")
 )
 
+// DBTX is satisfied by both *sql.DB and *sql.Tx, so models can be bound to
+// either.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 type Models struct {
-	Movies      MovieModel
-	Users       UserModel
-	Tokens      TokenModel
-	Permissions PermissionModel
+	db DBTX
+
+	Movies         MovieModel
+	Users          UserModel
+	Tokens         TokenModel
+	Permissions    PermissionModel
+	Revisions      RevisionModel
+	Reviews        ReviewModel
+	Watchlist      WatchlistModel
+	People         PersonModel
+	Credits        CreditModel
+	Genres         GenreModel
+	Images         ImageModel
+	Translations   TranslationModel
+	ExternalIDs    ExternalIDModel
+	Collections    CollectionModel
+	Releases       ReleaseModel
+	Certifications CertificationModel
 }
 
-func NewModels(db *sql.DB) *Models {
+func NewModels(db DBTX) *Models {
 	return &Models{
-		Movies:      MovieModel{DB: db},
-		Users:       UserModel{DB: db},
-		Tokens:      TokenModel{DB: db},
-		Permissions: PermissionModel{DB: db},
+		db:             db,
+		Movies:         MovieModel{DB: db},
+		Users:          UserModel{DB: db},
+		Tokens:         TokenModel{DB: db},
+		Permissions:    PermissionModel{DB: db},
+		Revisions:      RevisionModel{DB: db},
+		Reviews:        ReviewModel{DB: db},
+		Watchlist:      WatchlistModel{DB: db},
+		People:         PersonModel{DB: db},
+		Credits:        CreditModel{DB: db},
+		Genres:         GenreModel{DB: db},
+		Images:         ImageModel{DB: db},
+		Translations:   TranslationModel{DB: db},
+		ExternalIDs:    ExternalIDModel{DB: db},
+		Collections:    CollectionModel{DB: db},
+		Releases:       ReleaseModel{DB: db},
+		Certifications: CertificationModel{DB: db},
 	}
 }
+
+// Transaction runs fn with a copy of the models bound to a single
+// transaction, committing it only if fn returns nil.
+func (m Models) Transaction(ctx context.Context, fn func(Models) error) error {
+	return withTx(ctx, m.db, func(tx *sql.Tx) error {
+		return fn(*NewModels(tx))
+	})
+}
+
+// withTx runs fn in a new transaction. When db is already a transaction, fn
+// runs in a savepoint within it, so that a failure only undoes fn's work and
+// committing is left to whoever began the transaction.
+func withTx(ctx context.Context, db DBTX, fn func(*sql.Tx) error) error {
+	if tx, ok := db.(*sql.Tx); ok {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT nested"); err != nil {
+			return err
+		}
+
+		if err := fn(tx); err != nil {
+			if _, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT nested"); rollbackErr != nil {
+				return errors.Join(err, rollbackErr)
+			}
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT nested")
+		return err
+	}
+
+	beginner, ok := db.(interface {
+		BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error)
+	})
+	if !ok {
+		return errors.New("data: database handle cannot begin transactions")
+	}
+
+	tx, err := beginner.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,230 @@
+package data
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+const (
+	GenresModeAll  = "all"
+	GenresModeAny  = "any"
+	GenresModeNone = "none"
+)
+
+type MovieQuery struct {
+	Title                string
+	Genres               []string
+	GenresMode           string
+	YearMin              int
+	YearMax              int
+	RuntimeMin           int
+	RuntimeMax           int
+	PersonID             int64
+	CollectionID         int64
+	ReleasedIn           string
+	ReleasedBefore       Date
+	CertificationCountry string
+	CertificationMax     string
+	Search               string
+	SearchConfig         string
+	Highlight            bool
+	Fields               []string
+}
+
+// Fingerprint returns a short hash of the filters in q, which cursors record
+// so they cannot be replayed against a different result set. Highlight and
+// Fields only shape the response and are left out.
+func (q MovieQuery) Fingerprint() string {
+	q.Highlight = false
+	q.Fields = nil
+	q.Genres = slices.Clone(q.Genres)
+	slices.Sort(q.Genres)
+
+	js, err := json.Marshal(q)
+	if err != nil {
+		panic(err)
+	}
+
+	sum := sha256.Sum256(js)
+	return hex.EncodeToString(sum[:8])
+}
+
+func ValidateMovieQuery(v *validator.Validator, q MovieQuery) {
+	v.Check(validator.In(q.GenresMode, GenresModeAll, GenresModeAny, GenresModeNone), "genres_mode", "must be any, all or none")
+
+	v.Check(q.YearMin >= 0, "year_min", "must not be negative")
+	v.Check(q.YearMax >= 0, "year_max", "must not be negative")
+	if q.YearMin > 0 && q.YearMax > 0 {
+		v.Check(q.YearMin <= q.YearMax, "year_min", "must not be greater than year_max")
+	}
+
+	v.Check(q.RuntimeMin >= 0, "runtime_min", "must not be negative")
+	v.Check(q.RuntimeMax >= 0, "runtime_max", "must not be negative")
+	if q.RuntimeMin > 0 && q.RuntimeMax > 0 {
+		v.Check(q.RuntimeMin <= q.RuntimeMax, "runtime_min", "must not be greater than runtime_max")
+	}
+
+	v.Check(q.PersonID >= 0, "person_id", "must not be negative")
+	v.Check(q.CollectionID >= 0, "collection_id", "must not be negative")
+
+	if q.ReleasedIn != "" {
+		ValidateCountry(v, "released_in", q.ReleasedIn)
+	}
+	if q.CertificationMax != "" || q.CertificationCountry != "" {
+		ValidateCountry(v, "certification_country", q.CertificationCountry)
+		v.Check(q.CertificationMax != "", "certification_max", "must be provided")
+	}
+
+	v.Check(len(q.Search) <= 500, "q", "must not be more than 500 bytes long")
+	if q.Search != "" {
+		v.Check(ParseSearchQuery(q.Search) != "", "q", "must contain at least one search term")
+	}
+
+	v.Check(validator.In(q.SearchConfig, SearchConfigSafeList...), "search_config", "invalid search configuration")
+	v.Check(!q.Highlight || q.Search != "", "highlight", "requires the q parameter")
+
+	ValidateFields(v, q.Fields, MovieFieldSafeList)
+}
+
+type movieSQL struct {
+	args         queryArgs
+	conditions   []string
+	searchColumn string
+	searchQuery  string
+	searchConfig string
+}
+
+func (q MovieQuery) build() *movieSQL {
+	b := &movieSQL{}
+
+	b.conditions = append(b.conditions,
+		"deleted_at IS NULL",
+		fmt.Sprintf(`(%[1]s = '' OR title_search_simple @@ plainto_tsquery('simple', %[1]s) OR EXISTS (
+            SELECT 1 FROM movie_translations t
+            WHERE t.movie_id = movies.id AND t.title_search_simple @@ plainto_tsquery('simple', %[1]s)
+        ))`, b.args.add(q.Title)),
+	)
+
+	if len(q.Genres) > 0 {
+		genres := b.args.add(pq.Array(q.Genres))
+
+		switch q.GenresMode {
+		case GenresModeAny:
+			b.conditions = append(b.conditions, fmt.Sprintf("genres && %s", genres))
+		case GenresModeNone:
+			b.conditions = append(b.conditions, fmt.Sprintf("NOT (genres && %s)", genres))
+		default:
+			b.conditions = append(b.conditions, fmt.Sprintf("genres @> %s", genres))
+		}
+	}
+
+	if q.YearMin > 0 {
+		b.conditions = append(b.conditions, fmt.Sprintf("year >= %s", b.args.add(q.YearMin)))
+	}
+	if q.YearMax > 0 {
+		b.conditions = append(b.conditions, fmt.Sprintf("year <= %s", b.args.add(q.YearMax)))
+	}
+	if q.RuntimeMin > 0 {
+		b.conditions = append(b.conditions, fmt.Sprintf("runtime >= %s", b.args.add(q.RuntimeMin)))
+	}
+	if q.RuntimeMax > 0 {
+		b.conditions = append(b.conditions, fmt.Sprintf("runtime <= %s", b.args.add(q.RuntimeMax)))
+	}
+
+	if q.PersonID > 0 {
+		b.conditions = append(b.conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM movie_credits WHERE movie_credits.movie_id = movies.id AND movie_credits.person_id = %s)",
+			b.args.add(q.PersonID),
+		))
+	}
+
+	if q.CollectionID > 0 {
+		b.conditions = append(b.conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM collection_movies WHERE collection_movies.movie_id = movies.id AND collection_movies.collection_id = %s)",
+			b.args.add(q.CollectionID),
+		))
+	}
+
+	if q.ReleasedIn != "" || !q.ReleasedBefore.IsZero() {
+		released := []string{"r.movie_id = movies.id"}
+		if q.ReleasedIn != "" {
+			released = append(released, fmt.Sprintf("r.country = %s", b.args.add(q.ReleasedIn)))
+		}
+		if q.ReleasedBefore.IsZero() {
+			released = append(released, "r.release_date <= CURRENT_DATE")
+		} else {
+			released = append(released, fmt.Sprintf("r.release_date < %s", b.args.add(q.ReleasedBefore)))
+		}
+
+		b.conditions = append(b.conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM movie_releases r WHERE %s)",
+			strings.Join(released, " AND "),
+		))
+	}
+
+	if q.CertificationMax != "" {
+		country := b.args.add(q.CertificationCountry)
+		b.conditions = append(b.conditions, fmt.Sprintf(`EXISTS (
+            SELECT 1 FROM movie_certifications mc
+            INNER JOIN certifications c ON c.country = mc.country AND c.certification = mc.certification
+            WHERE mc.movie_id = movies.id AND mc.country = %[1]s
+              AND c.min_age <= (SELECT min_age FROM certifications WHERE country = %[1]s AND certification = %[2]s)
+        )`, country, b.args.add(q.CertificationMax)))
+	}
+
+	if tsquery := ParseSearchQuery(q.Search); tsquery != "" {
+		column, ok := searchColumns[q.SearchConfig]
+		if !ok {
+			panic("unsafe search configuration: " + q.SearchConfig)
+		}
+
+		b.searchColumn = column
+		b.searchConfig = q.SearchConfig
+		arg := b.args.add(tsquery)
+		b.searchQuery = fmt.Sprintf("to_tsquery('%s', %s)", q.SearchConfig, arg)
+		b.conditions = append(b.conditions, fmt.Sprintf(`(%s @@ %s OR EXISTS (
+            SELECT 1 FROM movie_translations t
+            WHERE t.movie_id = movies.id AND t.title_search_simple @@ to_tsquery('simple', %s)
+        ))`, b.searchColumn, b.searchQuery, arg))
+	}
+
+	return b
+}
+
+func (b *movieSQL) rank() string {
+	if b.searchQuery == "" {
+		return "0"
+	}
+
+	return fmt.Sprintf("ts_rank(%s, %s)", b.searchColumn, b.searchQuery)
+}
+
+// highlight wraps matches in <mark> tags. The title is HTML-escaped first so
+// that markup in a title comes back as text rather than as live HTML.
+func (b *movieSQL) highlight(enabled bool) string {
+	if !enabled || b.searchQuery == "" {
+		return "''"
+	}
+
+	escaped := "title"
+	for _, r := range [][2]string{{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&quot;"}, {"''", "&#39;"}} {
+		escaped = fmt.Sprintf("replace(%s, '%s', '%s')", escaped, r[0], r[1])
+	}
+
+	return fmt.Sprintf("ts_headline('%s', %s, %s, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')", b.searchConfig, escaped, b.searchQuery)
+}
+
+func (b *movieSQL) orderBy(filters Filters) string {
+	if filters.sortColumn() == "relevance" {
+		return fmt.Sprintf("%s DESC, id ASC", b.rank())
+	}
+
+	return fmt.Sprintf("%s %s, id ASC", filters.sortColumn(), filters.sortDirection())
+}
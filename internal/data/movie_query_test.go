@@ -0,0 +1,48 @@
+package data
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestMovieQueryGenresByAlias(t *testing.T) {
+	registry := NewGenreRegistry(false)
+	registry.Add("science-fiction", "sci-fi", "SF")
+	registry.Add("drama")
+
+	tbl := []struct {
+		input  []string
+		expect []string
+	}{
+		{input: []string{"sci-fi"}, expect: []string{"science-fiction"}},
+		{input: []string{"SF", "Drama"}, expect: []string{"science-fiction", "drama"}},
+		{input: []string{"Sci Fi", "science-fiction"}, expect: []string{"science-fiction"}},
+		{input: []string{"film noir", ""}, expect: []string{"film-noir"}},
+	}
+
+	for i, test := range tbl {
+		t.Run(fmt.Sprintf("Case %d", i+1), func(t *testing.T) {
+			q := MovieQuery{Genres: registry.CanonicalAll(test.input), GenresMode: GenresModeAll}
+			b := q.build()
+
+			if !slices.ContainsFunc(b.conditions, func(c string) bool { return strings.HasPrefix(c, "genres @> ") }) {
+				t.Fatalf("no genres condition in %v", b.conditions)
+			}
+
+			var got []string
+			for _, arg := range b.args {
+				if genres, ok := arg.(*pq.StringArray); ok {
+					got = *genres
+				}
+			}
+
+			if !slices.Equal(got, test.expect) {
+				t.Fatalf("got %q, want %q", got, test.expect)
+			}
+		})
+	}
+}
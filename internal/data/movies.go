@@ -3,8 +3,12 @@ package data
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
@@ -12,22 +16,70 @@ import (
 )
 
 type Movie struct {
-	ID        int       `json:"id"`
-	CreatedAt time.Time `json:"-"`
-	Title     string    `json:"title"`
-	Year      int       `json:"year,omitempty"`
-	Runtime   Runtime   `json:"runtime,omitempty"`
-	Genres    []string  `json:"genres,omitempty"`
-	Version   int       `json:"version"`
+	ID            int               `json:"id"`
+	CreatedAt     time.Time         `json:"-"`
+	Title         string            `json:"title"`
+	OriginalTitle string            `json:"original_title,omitempty"`
+	Locale        string            `json:"locale,omitempty"`
+	Synopsis      string            `json:"synopsis,omitempty"`
+	Year          int               `json:"year,omitempty"`
+	Announced     bool              `json:"announced,omitempty"`
+	Runtime       Runtime           `json:"runtime,omitempty"`
+	Genres        []string          `json:"genres,omitempty"`
+	Version       int               `json:"version"`
+	Rating        float64           `json:"rating"`
+	RatingCount   int               `json:"rating_count"`
+	Poster        *Image            `json:"poster,omitempty"`
+	ExternalIDs   map[string]string `json:"external_ids,omitempty"`
+	DeletedAt     *time.Time        `json:"deleted_at,omitempty"`
+	Highlight     string            `json:"highlight,omitempty"`
+
+	runtimeFormat RuntimeFormat
 }
 
-func ValidateMovie(v *validator.Validator, movie Movie) {
+// SetRuntimeFormat sets the format the runtime is rendered in when the movie
+// is encoded as JSON.
+func (m *Movie) SetRuntimeFormat(format RuntimeFormat) {
+	m.runtimeFormat = format
+}
+
+// Translate replaces the title with the given translation, keeping the
+// original title alongside it. A nil translation leaves the title as it is.
+func (m *Movie) Translate(translation *Translation) {
+	m.OriginalTitle = m.Title
+
+	if translation != nil {
+		m.Title = translation.Title
+		m.Locale = translation.Locale
+		m.Synopsis = translation.Synopsis
+	}
+}
+
+func (m Movie) MarshalJSON() ([]byte, error) {
+	type movie Movie
+
+	var runtime any
+	if m.Runtime != 0 {
+		runtime = m.Runtime.Format(m.runtimeFormat)
+	}
+
+	return json.Marshal(struct {
+		movie
+		Runtime any `json:"runtime,omitempty"`
+	}{movie(m), runtime})
+}
+
+func ValidateMovie(v *validator.Validator, movie *Movie, genres *GenreRegistry) {
 	v.Check(movie.Title != "", "title", "must be provided")
 	v.Check(len(movie.Title) <= 500, "title", "must not be more than 500 bytes long")
 
 	v.Check(movie.Year != 0, "year", "must be provided")
 	v.Check(movie.Year >= 1888, "year", "must be greater than 1888")
-	v.Check(movie.Year <= time.Now().Year(), "year", "must not be in the future")
+	if movie.Announced {
+		v.Check(movie.Year <= time.Now().Year()+10, "year", "must not be more than 10 years in the future")
+	} else {
+		v.Check(movie.Year <= time.Now().Year(), "year", "must not be in the future unless the movie is announced")
+	}
 
 	v.Check(movie.Runtime != 0, "runtime", "must be provided")
 	v.Check(movie.Runtime > 0, "runtime", "must be a positive integer")
@@ -35,17 +87,27 @@ func ValidateMovie(v *validator.Validator, movie Movie) {
 	v.Check(movie.Genres != nil, "genres", "must be provided")
 	v.Check(len(movie.Genres) >= 1, "genres", "must contain at least 1 genre")
 	v.Check(len(movie.Genres) <= 5, "genres", "must not contain more than 5 genres")
+
+	if genres != nil {
+		for i, genre := range movie.Genres {
+			slug, ok := genres.Canonical(genre)
+			v.Check(ok || !genres.Strict, "genres", fmt.Sprintf("unknown genre %q", genre))
+			v.Check(slug != "", "genres", "must not contain empty values")
+			movie.Genres[i] = slug
+		}
+	}
+
 	v.Check(validator.Unique(movie.Genres), "genres", "must not contain duplicate values")
 }
 
 type MovieModel struct {
-	DB *sql.DB
+	DB DBTX
 }
 
 func (m MovieModel) Insert(movie *Movie) error {
 	query := `
-        INSERT INTO movies (title, year, runtime, genres)
-        VALUES ($1, $2, $3, $4)
+        INSERT INTO movies (title, year, runtime, genres, announced)
+        VALUES ($1, $2, $3, $4, $5)
         RETURNING id, created_at, version
     `
 
@@ -59,6 +121,7 @@ func (m MovieModel) Insert(movie *Movie) error {
 		movie.Year,
 		movie.Runtime,
 		pq.Array(movie.Genres),
+		movie.Announced,
 	).Scan(
 		&movie.ID,
 		&movie.CreatedAt,
@@ -66,30 +129,65 @@ func (m MovieModel) Insert(movie *Movie) error {
 	)
 }
 
+func (m MovieModel) InsertMany(movies []*Movie) error {
+	query := `
+        INSERT INTO movies (title, year, runtime, genres, announced)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at, version
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return withTx(ctx, m.DB, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, movie := range movies {
+			if err := stmt.QueryRowContext(
+				ctx,
+				movie.Title,
+				movie.Year,
+				movie.Runtime,
+				pq.Array(movie.Genres),
+				movie.Announced,
+			).Scan(
+				&movie.ID,
+				&movie.CreatedAt,
+				&movie.Version,
+			); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
 func (m MovieModel) Get(id int) (*Movie, error) {
+	return m.GetFields(id, nil)
+}
+
+func (m MovieModel) GetFields(id int, fields []string) (*Movie, error) {
 	if id < 1 {
 		return nil, ErrRecordNotFound
 	}
 
 	var movie Movie
-	query := `
-        SELECT id, created_at, title, year, runtime, genres, version
+	columns := movieSelection(fields)
+	query := fmt.Sprintf(`
+        SELECT %s
         FROM movies
-        WHERE id = $1
-    `
+        WHERE id = $1 AND deleted_at IS NULL
+    `, strings.Join(columns, ", "))
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	if err := m.DB.QueryRowContext(ctx, query, id).Scan(
-		&movie.ID,
-		&movie.CreatedAt,
-		&movie.Title,
-		&movie.Year,
-		&movie.Runtime,
-		pq.Array(&movie.Genres),
-		&movie.Version,
-	); err != nil {
+	if err := m.DB.QueryRowContext(ctx, query, id).Scan(movieDestinations(&movie, columns)...); err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			return nil, ErrRecordNotFound
@@ -108,8 +206,8 @@ func (m MovieModel) Update(movie *Movie) error {
 
 	query := `
         UPDATE movies
-        SET title = $1, year = $2, runtime = $3, genres = $4, version = version + 1
-        WHERE id = $5 and version = $6
+        SET title = $1, year = $2, runtime = $3, genres = $4, announced = $5, version = version + 1
+        WHERE id = $6 AND version = $7 AND deleted_at IS NULL
         RETURNING version
     `
 
@@ -123,6 +221,7 @@ func (m MovieModel) Update(movie *Movie) error {
 		movie.Year,
 		movie.Runtime,
 		pq.Array(movie.Genres),
+		movie.Announced,
 		movie.ID,
 		movie.Version,
 	).Scan(
@@ -145,7 +244,9 @@ func (m MovieModel) Delete(id int) error {
 	}
 
 	query := `
-        DELETE FROM movies WHERE id = $1
+        UPDATE movies
+        SET deleted_at = NOW()
+        WHERE id = $1 AND deleted_at IS NULL
     `
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -168,20 +269,49 @@ func (m MovieModel) Delete(id int) error {
 	return nil
 }
 
-func (m MovieModel) GetAll(title string, genres []string, filters Filters) ([]*Movie, PageInfo, error) {
+func (m MovieModel) Restore(id int) (*Movie, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	var movie Movie
+	columns := movieSelection(nil)
 	query := fmt.Sprintf(`
-        SELECT count(*) OVER(), id, created_at, title, year, runtime, genres, version
+        UPDATE movies
+        SET deleted_at = NULL
+        WHERE id = $1 AND deleted_at IS NOT NULL AND merged_into IS NULL
+        RETURNING %s
+    `, strings.Join(columns, ", "))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := m.DB.QueryRowContext(ctx, query, id).Scan(movieDestinations(&movie, columns)...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &movie, nil
+}
+
+func (m MovieModel) GetTrash(filters Filters) ([]*Movie, PageInfo, error) {
+	columns := movieSelection(nil)
+	query := fmt.Sprintf(`
+        SELECT count(*) OVER(), %s, deleted_at
         FROM movies
-        WHERE (to_tsvector('simple', title) @@ plainto_tsquery('simple', $1) OR $1 = '')
-        AND (genres @> $2 OR $2 = '{}')
+        WHERE deleted_at IS NOT NULL AND merged_into IS NULL
         ORDER BY %s %s, id ASC
-        LIMIT $3 OFFSET $4
-    `, filters.sortColumn(), filters.sortDirection())
+        LIMIT $1 OFFSET $2
+    `, strings.Join(columns, ", "), filters.sortColumn(), filters.sortDirection())
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	rows, err := m.DB.QueryContext(ctx, query, title, pq.Array(genres), filters.limit(), filters.offset())
+	rows, err := m.DB.QueryContext(ctx, query, filters.limit(), filters.offset())
 	if err != nil {
 		return nil, PageInfo{}, err
 	}
@@ -192,16 +322,8 @@ func (m MovieModel) GetAll(title string, genres []string, filters Filters) ([]*M
 	for rows.Next() {
 		var movie Movie
 
-		err := rows.Scan(
-			&totalRecords,
-			&movie.ID,
-			&movie.CreatedAt,
-			&movie.Title,
-			&movie.Year,
-			&movie.Runtime,
-			pq.Array(&movie.Genres),
-			&movie.Version,
-		)
+		dests := append([]any{&totalRecords}, movieDestinations(&movie, columns)...)
+		err := rows.Scan(append(dests, &movie.DeletedAt)...)
 		if err != nil {
 			return nil, PageInfo{}, err
 		}
@@ -217,3 +339,264 @@ func (m MovieModel) GetAll(title string, genres []string, filters Filters) ([]*M
 
 	return movies, pageInfo, nil
 }
+
+// PurgeDeleted permanently deletes movies that have been in the trash for
+// longer than olderThan. Along with their IDs it returns the storage prefixes
+// of their images, which may live under another movie's prefix if the images
+// were moved over by a merge.
+func (m MovieModel) PurgeDeleted(olderThan time.Duration) ([]int, []string, error) {
+	query := `
+        WITH purged AS (
+            DELETE FROM movies
+            WHERE deleted_at IS NOT NULL AND deleted_at < $1 AND merged_into IS NULL
+            RETURNING id
+        )
+        SELECT p.id, i.storage_prefix
+        FROM purged p
+        LEFT JOIN movie_images i ON i.movie_id = p.id
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, time.Now().Add(-olderThan))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	var prefixes []string
+	for rows.Next() {
+		var id int
+		var prefix sql.NullString
+
+		if err := rows.Scan(&id, &prefix); err != nil {
+			return nil, nil, err
+		}
+
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+		if prefix.Valid {
+			prefixes = append(prefixes, prefix.String)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return ids, prefixes, nil
+}
+
+func (m MovieModel) GetAll(q MovieQuery, filters Filters) ([]*Movie, PageInfo, error) {
+	if filters.UseCursor {
+		return m.getAllByCursor(q, filters)
+	}
+
+	b := q.build()
+	columns := movieSelection(q.Fields)
+
+	query := fmt.Sprintf(`
+        SELECT count(*) OVER(), %s, %s
+        FROM movies
+        WHERE %s
+        ORDER BY %s
+        LIMIT %s OFFSET %s
+    `, strings.Join(columns, ", "), b.highlight(q.Highlight), strings.Join(b.conditions, " AND "), b.orderBy(filters), b.args.add(filters.limit()), b.args.add(filters.offset()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	defer rows.Close()
+
+	var totalRecords int
+	movies := []*Movie{}
+	for rows.Next() {
+		var movie Movie
+
+		dests := append([]any{&totalRecords}, movieDestinations(&movie, columns)...)
+		err := rows.Scan(append(dests, &movie.Highlight)...)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+
+		movies = append(movies, &movie)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	pageInfo := calculatePageInfo(totalRecords, filters.Page, filters.PageSize)
+
+	return movies, pageInfo, nil
+}
+
+func (m MovieModel) getAllByCursor(q MovieQuery, filters Filters) ([]*Movie, PageInfo, error) {
+	b := q.build()
+
+	column := filters.sortColumn()
+	order := b.orderBy(filters)
+	columns := movieSelection(q.Fields, column)
+
+	var after *cursor
+	if filters.Cursor != "" {
+		c, err := decodeCursor(filters.Cursor)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+		after = &c
+
+		valueOp, idOp := ">", ">"
+		if filters.sortDirection() == "DESC" {
+			valueOp = "<"
+		}
+		if after.Direction == cursorPrev {
+			valueOp, idOp = flipComparison(valueOp), "<"
+			order = fmt.Sprintf("%s %s, id DESC", column, filters.reverseSortDirection())
+		}
+
+		b.conditions = append(b.conditions, fmt.Sprintf(
+			"(%[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND id %[4]s %[5]s))",
+			column, valueOp, b.args.add(after.Value), idOp, b.args.add(after.ID),
+		))
+	}
+
+	query := fmt.Sprintf(`
+        SELECT %s, %s
+        FROM movies
+        WHERE %s
+        ORDER BY %s
+        LIMIT %s
+    `, strings.Join(columns, ", "), b.highlight(q.Highlight), strings.Join(b.conditions, " AND "), order, b.args.add(filters.limit()+1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	defer rows.Close()
+
+	movies := []*Movie{}
+	for rows.Next() {
+		var movie Movie
+
+		err := rows.Scan(append(movieDestinations(&movie, columns), &movie.Highlight)...)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+
+		movies = append(movies, &movie)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	hasMore := len(movies) > filters.limit()
+	if hasMore {
+		movies = movies[:filters.limit()]
+	}
+
+	backwards := after != nil && after.Direction == cursorPrev
+	if backwards {
+		slices.Reverse(movies)
+	}
+
+	pageInfo := PageInfo{PageSize: filters.PageSize}
+	if len(movies) == 0 {
+		return movies, pageInfo, nil
+	}
+
+	first, last := movies[0], movies[len(movies)-1]
+	if (backwards && hasMore) || (!backwards && after != nil) {
+		pageInfo.PrevCursor = cursor{
+			Sort:      filters.Sort,
+			Scope:     filters.CursorScope,
+			Value:     first.sortValue(column),
+			ID:        first.ID,
+			Direction: cursorPrev,
+		}.encode()
+	}
+	if backwards || hasMore {
+		pageInfo.NextCursor = cursor{
+			Sort:      filters.Sort,
+			Scope:     filters.CursorScope,
+			Value:     last.sortValue(column),
+			ID:        last.ID,
+			Direction: cursorNext,
+		}.encode()
+	}
+
+	return movies, pageInfo, nil
+}
+
+// Stream calls fn for every movie matching q, in id order. The query is bound
+// to ctx, so it stops when the caller goes away.
+func (m MovieModel) Stream(ctx context.Context, q MovieQuery, fn func(*Movie) error) error {
+	b := q.build()
+	columns := movieSelection(nil)
+
+	query := fmt.Sprintf(`
+        SELECT %s
+        FROM movies
+        WHERE %s
+        ORDER BY id ASC
+    `, strings.Join(columns, ", "), strings.Join(b.conditions, " AND "))
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movie Movie
+
+		if err := rows.Scan(movieDestinations(&movie, columns)...); err != nil {
+			return err
+		}
+
+		if err := fn(&movie); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (movie *Movie) sortValue(column string) string {
+	switch column {
+	case "id":
+		return strconv.Itoa(movie.ID)
+	case "title":
+		return movie.Title
+	case "year":
+		return strconv.Itoa(movie.Year)
+	case "runtime":
+		return strconv.Itoa(int(movie.Runtime))
+	case "rating":
+		return strconv.FormatFloat(movie.Rating, 'f', -1, 64)
+	default:
+		panic("unknown sort column: " + column)
+	}
+}
+
+func flipComparison(op string) string {
+	if op == ">" {
+		return "<"
+	}
+
+	return ">"
+}
@@ -0,0 +1,203 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+type Person struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"-"`
+	Name      string    `json:"name"`
+	BirthDate *Date     `json:"birth_date,omitempty"`
+	Biography string    `json:"biography,omitempty"`
+	Version   int       `json:"version"`
+}
+
+func ValidatePerson(v *validator.Validator, person *Person) {
+	v.Check(person.Name != "", "name", "must be provided")
+	v.Check(len(person.Name) <= 500, "name", "must not be more than 500 bytes long")
+
+	if person.BirthDate != nil {
+		v.Check(person.BirthDate.Year() >= 1800, "birth_date", "must be after 1800")
+		v.Check(person.BirthDate.Before(time.Now()), "birth_date", "must not be in the future")
+	}
+
+	v.Check(len(person.Biography) <= 10_000, "biography", "must not be more than 10000 bytes long")
+}
+
+type PersonModel struct {
+	DB DBTX
+}
+
+func (m PersonModel) Insert(person *Person) error {
+	query := `
+        INSERT INTO people (name, birth_date, biography)
+        VALUES ($1, $2, $3)
+        RETURNING id, created_at, version
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(
+		ctx,
+		query,
+		person.Name,
+		person.BirthDate,
+		person.Biography,
+	).Scan(
+		&person.ID,
+		&person.CreatedAt,
+		&person.Version,
+	)
+}
+
+func (m PersonModel) Get(id int64) (*Person, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+        SELECT id, created_at, name, birth_date, biography, version
+        FROM people
+        WHERE id = $1
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var person Person
+	if err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&person.ID,
+		&person.CreatedAt,
+		&person.Name,
+		&person.BirthDate,
+		&person.Biography,
+		&person.Version,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &person, nil
+}
+
+func (m PersonModel) Update(person *Person) error {
+	query := `
+        UPDATE people
+        SET name = $1, birth_date = $2, biography = $3, version = version + 1
+        WHERE id = $4 AND version = $5
+        RETURNING version
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := m.DB.QueryRowContext(
+		ctx,
+		query,
+		person.Name,
+		person.BirthDate,
+		person.Biography,
+		person.ID,
+		person.Version,
+	).Scan(
+		&person.Version,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m PersonModel) Delete(id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+        DELETE FROM people WHERE id = $1
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m PersonModel) GetAll(name string, filters Filters) ([]*Person, PageInfo, error) {
+	query := fmt.Sprintf(`
+        SELECT count(*) OVER(), id, created_at, name, birth_date, biography, version
+        FROM people
+        WHERE (to_tsvector('simple', name) @@ plainto_tsquery('simple', $1) OR $1 = '')
+        ORDER BY %s %s, id ASC
+        LIMIT $2 OFFSET $3
+    `, filters.sortColumn(), filters.sortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, name, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	defer rows.Close()
+
+	var totalRecords int
+	people := []*Person{}
+	for rows.Next() {
+		var person Person
+
+		err := rows.Scan(
+			&totalRecords,
+			&person.ID,
+			&person.CreatedAt,
+			&person.Name,
+			&person.BirthDate,
+			&person.Biography,
+			&person.Version,
+		)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+
+		people = append(people, &person)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	pageInfo := calculatePageInfo(totalRecords, filters.Page, filters.PageSize)
+
+	return people, pageInfo, nil
+}
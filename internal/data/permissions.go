@@ -2,7 +2,6 @@ package data
 
 import (
 	"context"
-	"database/sql"
 	"slices"
 	"time"
 
@@ -16,7 +15,7 @@ func (p Permissions) Include(code string) bool {
 }
 
 type PermissionModel struct {
-	DB *sql.DB
+	DB DBTX
 }
 
 func (m PermissionModel) GetAllForUser(userID int64) (Permissions, error) {
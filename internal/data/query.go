@@ -0,0 +1,10 @@
+package data
+
+import "fmt"
+
+type queryArgs []any
+
+func (a *queryArgs) add(value any) string {
+	*a = append(*a, value)
+	return fmt.Sprintf("$%d", len(*a))
+}
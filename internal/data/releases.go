@@ -0,0 +1,311 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+var (
+	ErrDuplicateRelease     = errors.New("duplicate release")
+	ErrUnknownCertification = errors.New("unknown certification")
+)
+
+var ReleaseKindSafeList = []string{"theatrical", "digital", "physical"}
+
+var CountryRX = regexp.MustCompile(`^[A-Z]{2}$`)
+
+type Release struct {
+	ID      int64  `json:"id"`
+	MovieID int    `json:"-"`
+	Country string `json:"country"`
+	Kind    string `json:"kind"`
+	Date    Date   `json:"date"`
+	Note    string `json:"note,omitempty"`
+}
+
+func ValidateCountry(v *validator.Validator, key, country string) {
+	v.Check(country != "", key, "must be provided")
+	v.Check(validator.Matches(*CountryRX, country), key, "must be an ISO 3166-1 alpha-2 country code")
+}
+
+func ValidateRelease(v *validator.Validator, release *Release) {
+	ValidateCountry(v, "country", release.Country)
+
+	v.Check(release.Kind != "", "kind", "must be provided")
+	v.Check(validator.In(release.Kind, ReleaseKindSafeList...), "kind", "must be theatrical, digital or physical")
+
+	v.Check(!release.Date.IsZero(), "date", "must be provided")
+	v.Check(release.Date.Year() >= 1888, "date", "must not be before 1888")
+
+	v.Check(len(release.Note) <= 500, "note", "must not be more than 500 bytes long")
+}
+
+type ReleaseModel struct {
+	DB DBTX
+}
+
+func (m ReleaseModel) Insert(release *Release) error {
+	query := `
+        INSERT INTO movie_releases (movie_id, country, kind, release_date, note)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(
+		ctx,
+		query,
+		release.MovieID,
+		release.Country,
+		release.Kind,
+		release.Date,
+		release.Note,
+	).Scan(
+		&release.ID,
+	)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "movie_releases_movie_id_country_kind_key"`:
+			return ErrDuplicateRelease
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m ReleaseModel) Delete(movieID int, id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+        DELETE FROM movie_releases
+        WHERE id = $1 AND movie_id = $2
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, movieID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m ReleaseModel) GetAllForMovie(movieID int) ([]*Release, error) {
+	query := `
+        SELECT id, movie_id, country, kind, release_date, note
+        FROM movie_releases
+        WHERE movie_id = $1
+        ORDER BY release_date, country, kind
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, movieID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	releases := []*Release{}
+	for rows.Next() {
+		var release Release
+
+		err := rows.Scan(
+			&release.ID,
+			&release.MovieID,
+			&release.Country,
+			&release.Kind,
+			&release.Date,
+			&release.Note,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		releases = append(releases, &release)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+type Certification struct {
+	MovieID       int    `json:"-"`
+	Country       string `json:"country"`
+	Certification string `json:"certification"`
+	MinAge        int    `json:"min_age"`
+}
+
+func ValidateCertification(v *validator.Validator, certification *Certification) {
+	ValidateCountry(v, "country", certification.Country)
+
+	v.Check(certification.Certification != "", "certification", "must be provided")
+	v.Check(len(certification.Certification) <= 20, "certification", "must not be more than 20 bytes long")
+}
+
+type CertificationModel struct {
+	DB DBTX
+}
+
+// Upsert sets the movie's certification in a country, replacing any existing
+// one. It returns ErrUnknownCertification if the certification isn't part of
+// the country's rating system.
+func (m CertificationModel) Upsert(certification *Certification) (bool, error) {
+	query := `
+        INSERT INTO movie_certifications (movie_id, country, certification)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (movie_id, country) DO UPDATE
+        SET certification = EXCLUDED.certification
+        RETURNING (SELECT min_age FROM certifications WHERE country = $2 AND certification = $3), xmax = 0
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var inserted bool
+	err := m.DB.QueryRowContext(
+		ctx,
+		query,
+		certification.MovieID,
+		certification.Country,
+		certification.Certification,
+	).Scan(
+		&certification.MinAge,
+		&inserted,
+	)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: insert or update on table "movie_certifications" violates foreign key constraint "movie_certifications_country_certification_fkey"`:
+			return false, ErrUnknownCertification
+		default:
+			return false, err
+		}
+	}
+
+	return inserted, nil
+}
+
+func (m CertificationModel) Delete(movieID int, country string) error {
+	query := `
+        DELETE FROM movie_certifications
+        WHERE movie_id = $1 AND country = $2
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, movieID, country)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Exists reports whether the certification is part of the country's rating
+// system.
+func (m CertificationModel) Exists(country, certification string) (bool, error) {
+	query := `
+        SELECT EXISTS (SELECT 1 FROM certifications WHERE country = $1 AND certification = $2)
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var exists bool
+	err := m.DB.QueryRowContext(ctx, query, country, certification).Scan(&exists)
+
+	return exists, err
+}
+
+// GetAll returns the known certifications, ordered by country and then from
+// least to most restrictive.
+func (m CertificationModel) GetAll() ([]*Certification, error) {
+	query := `
+        SELECT 0, country, certification, min_age
+        FROM certifications
+        ORDER BY country, min_age, certification
+    `
+
+	return m.query(query)
+}
+
+func (m CertificationModel) GetAllForMovie(movieID int) ([]*Certification, error) {
+	query := `
+        SELECT mc.movie_id, mc.country, mc.certification, c.min_age
+        FROM movie_certifications mc
+        INNER JOIN certifications c ON c.country = mc.country AND c.certification = mc.certification
+        WHERE mc.movie_id = $1
+        ORDER BY mc.country
+    `
+
+	return m.query(query, movieID)
+}
+
+func (m CertificationModel) query(query string, args ...any) ([]*Certification, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	certifications := []*Certification{}
+	for rows.Next() {
+		var certification Certification
+
+		err := rows.Scan(
+			&certification.MovieID,
+			&certification.Country,
+			&certification.Certification,
+			&certification.MinAge,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		certifications = append(certifications, &certification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return certifications, nil
+}
@@ -0,0 +1,218 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+var ErrDuplicateReview = errors.New("duplicate review")
+
+type Review struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	MovieID   int       `json:"movie_id"`
+	UserID    int64     `json:"user_id"`
+	Rating    int       `json:"rating"`
+	Body      string    `json:"body,omitempty"`
+	Version   int       `json:"version"`
+}
+
+func ValidateReview(v *validator.Validator, review *Review) {
+	v.Check(review.Rating != 0, "rating", "must be provided")
+	v.Check(review.Rating >= 1 && review.Rating <= 10, "rating", "must be between 1 and 10")
+
+	v.Check(len(review.Body) <= 10_000, "body", "must not be more than 10000 bytes long")
+}
+
+type ReviewModel struct {
+	DB DBTX
+}
+
+func (m ReviewModel) Insert(review *Review) error {
+	query := `
+        INSERT INTO reviews (movie_id, user_id, rating, body)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at, updated_at, version
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(
+		ctx,
+		query,
+		review.MovieID,
+		review.UserID,
+		review.Rating,
+		review.Body,
+	).Scan(
+		&review.ID,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+		&review.Version,
+	)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "reviews_movie_id_user_id_key"`:
+			return ErrDuplicateReview
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m ReviewModel) Get(id int64) (*Review, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+        SELECT id, created_at, updated_at, movie_id, user_id, rating, body, version
+        FROM reviews
+        WHERE id = $1
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var review Review
+	if err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&review.ID,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+		&review.MovieID,
+		&review.UserID,
+		&review.Rating,
+		&review.Body,
+		&review.Version,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &review, nil
+}
+
+func (m ReviewModel) Update(review *Review) error {
+	query := `
+        UPDATE reviews
+        SET rating = $1, body = $2, updated_at = NOW(), version = version + 1
+        WHERE id = $3 AND version = $4
+        RETURNING updated_at, version
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := m.DB.QueryRowContext(
+		ctx,
+		query,
+		review.Rating,
+		review.Body,
+		review.ID,
+		review.Version,
+	).Scan(
+		&review.UpdatedAt,
+		&review.Version,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m ReviewModel) Delete(id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+        DELETE FROM reviews WHERE id = $1
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m ReviewModel) GetAllForMovie(movieID int, filters Filters) ([]*Review, PageInfo, error) {
+	query := fmt.Sprintf(`
+        SELECT count(*) OVER(), id, created_at, updated_at, movie_id, user_id, rating, body, version
+        FROM reviews
+        WHERE movie_id = $1
+        ORDER BY %s %s, id ASC
+        LIMIT $2 OFFSET $3
+    `, filters.sortColumn(), filters.sortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, movieID, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	defer rows.Close()
+
+	var totalRecords int
+	reviews := []*Review{}
+	for rows.Next() {
+		var review Review
+
+		err := rows.Scan(
+			&totalRecords,
+			&review.ID,
+			&review.CreatedAt,
+			&review.UpdatedAt,
+			&review.MovieID,
+			&review.UserID,
+			&review.Rating,
+			&review.Body,
+			&review.Version,
+		)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+
+		reviews = append(reviews, &review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	pageInfo := calculatePageInfo(totalRecords, filters.Page, filters.PageSize)
+
+	return reviews, pageInfo, nil
+}
@@ -0,0 +1,191 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	RevisionInsert  = "insert"
+	RevisionUpdate  = "update"
+	RevisionDelete  = "delete"
+	RevisionRestore = "restore"
+	RevisionMerge   = "merge"
+)
+
+type Revision struct {
+	ID        int64     `json:"id"`
+	MovieID   int       `json:"movie_id"`
+	Version   int       `json:"version"`
+	Action    string    `json:"action"`
+	UserID    *int64    `json:"user_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Snapshot  Movie     `json:"snapshot"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+func DiffMovies(from, to Movie) []FieldChange {
+	changes := []FieldChange{}
+
+	if from.Title != to.Title {
+		changes = append(changes, FieldChange{Field: "title", From: from.Title, To: to.Title})
+	}
+	if from.Year != to.Year {
+		changes = append(changes, FieldChange{Field: "year", From: from.Year, To: to.Year})
+	}
+	if from.Announced != to.Announced {
+		changes = append(changes, FieldChange{Field: "announced", From: from.Announced, To: to.Announced})
+	}
+	if from.Runtime != to.Runtime {
+		changes = append(changes, FieldChange{Field: "runtime", From: from.Runtime, To: to.Runtime})
+	}
+	if !slices.Equal(from.Genres, to.Genres) {
+		changes = append(changes, FieldChange{Field: "genres", From: from.Genres, To: to.Genres})
+	}
+
+	return changes
+}
+
+type RevisionModel struct {
+	DB DBTX
+}
+
+func (m RevisionModel) Insert(revision *Revision) error {
+	query := `
+        INSERT INTO movie_revisions (movie_id, version, action, user_id, snapshot)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at
+    `
+
+	// Snapshots are always stored with the runtime in minutes, whatever format
+	// the request that produced them asked for.
+	revision.Snapshot.SetRuntimeFormat(RuntimeFormatMins)
+
+	snapshot, err := json.Marshal(revision.Snapshot)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(
+		ctx,
+		query,
+		revision.MovieID,
+		revision.Version,
+		revision.Action,
+		revision.UserID,
+		snapshot,
+	).Scan(
+		&revision.ID,
+		&revision.CreatedAt,
+	)
+}
+
+func (m RevisionModel) GetAllForMovie(movieID int, filters Filters) ([]*Revision, PageInfo, error) {
+	query := fmt.Sprintf(`
+        SELECT count(*) OVER(), id, movie_id, version, action, user_id, created_at, snapshot
+        FROM movie_revisions
+        WHERE movie_id = $1
+        ORDER BY %s %s, id %s
+        LIMIT $2 OFFSET $3
+    `, filters.sortColumn(), filters.sortDirection(), filters.sortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, movieID, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	defer rows.Close()
+
+	var totalRecords int
+	revisions := []*Revision{}
+	for rows.Next() {
+		var revision Revision
+		var snapshot []byte
+
+		err := rows.Scan(
+			&totalRecords,
+			&revision.ID,
+			&revision.MovieID,
+			&revision.Version,
+			&revision.Action,
+			&revision.UserID,
+			&revision.CreatedAt,
+			&snapshot,
+		)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+
+		if err := json.Unmarshal(snapshot, &revision.Snapshot); err != nil {
+			return nil, PageInfo{}, err
+		}
+
+		revisions = append(revisions, &revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	pageInfo := calculatePageInfo(totalRecords, filters.Page, filters.PageSize)
+
+	return revisions, pageInfo, nil
+}
+
+func (m RevisionModel) GetVersion(movieID, version int) (*Revision, error) {
+	if movieID < 1 || version < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+        SELECT id, movie_id, version, action, user_id, created_at, snapshot
+        FROM movie_revisions
+        WHERE movie_id = $1 AND version = $2 AND action <> $3
+        ORDER BY id DESC
+        LIMIT 1
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var revision Revision
+	var snapshot []byte
+
+	if err := m.DB.QueryRowContext(ctx, query, movieID, version, RevisionDelete).Scan(
+		&revision.ID,
+		&revision.MovieID,
+		&revision.Version,
+		&revision.Action,
+		&revision.UserID,
+		&revision.CreatedAt,
+		&snapshot,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if err := json.Unmarshal(snapshot, &revision.Snapshot); err != nil {
+		return nil, err
+	}
+
+	return &revision, nil
+}
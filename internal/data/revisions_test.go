@@ -0,0 +1,25 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffMovies(t *testing.T) {
+	from := Movie{Title: "Alien", Year: 1979, Runtime: 117, Genres: []string{"horror"}}
+	to := Movie{Title: "Alien", Year: 1979, Runtime: 116, Genres: []string{"horror", "sci-fi"}}
+
+	got := DiffMovies(from, to)
+	want := []FieldChange{
+		{Field: "runtime", From: Runtime(117), To: Runtime(116)},
+		{Field: "genres", From: []string{"horror"}, To: []string{"horror", "sci-fi"}},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	if changes := DiffMovies(from, from); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", changes)
+	}
+}
@@ -1,40 +1,119 @@
 package data
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 )
 
-var ErrInvalidRuntimeFormat = errors.New("invalid runtime format")
+var ErrInvalidRuntimeFormat = errors.New(`invalid runtime format: use a number of minutes or a string such as "105 mins", "105 minutes", "1h 45m" or "PT1H45M"`)
+
+type RuntimeFormat string
+
+const (
+	RuntimeFormatMins    RuntimeFormat = "mins"
+	RuntimeFormatHM      RuntimeFormat = "hm"
+	RuntimeFormatISO8601 RuntimeFormat = "iso8601"
+	RuntimeFormatSeconds RuntimeFormat = "seconds"
+)
+
+var RuntimeFormatSafeList = []string{
+	string(RuntimeFormatMins),
+	string(RuntimeFormatHM),
+	string(RuntimeFormatISO8601),
+	string(RuntimeFormatSeconds),
+}
+
+var (
+	runtimeHoursMinutesRX = regexp.MustCompile(`^(?:(\d+)\s*h(?:ours?|rs?)?)?\s*(?:(\d+)\s*m(?:ins?|inutes?)?)?$`)
+	runtimeISO8601RX      = regexp.MustCompile(`^PT(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?$`)
+)
 
 type Runtime int
 
 func (r Runtime) MarshalJSON() ([]byte, error) {
-	return []byte(strconv.Quote(fmt.Sprintf("%d mins", r))), nil
+	return json.Marshal(r.Format(RuntimeFormatMins))
 }
 
 func (r *Runtime) UnmarshalJSON(jsonValue []byte) error {
 	value, err := strconv.Unquote(string(jsonValue))
 	if err != nil {
-		return ErrInvalidRuntimeFormat
+		value = string(jsonValue)
+	}
+
+	runtime, err := ParseRuntime(value)
+	if err != nil {
+		return err
+	}
+
+	*r = runtime
+
+	return nil
+}
+
+// Format returns the runtime in the given format. Every format is a string
+// except seconds, which is a number.
+func (r Runtime) Format(format RuntimeFormat) any {
+	switch format {
+	case RuntimeFormatHM:
+		hours, minutes := int(r)/60, int(r)%60
+		switch {
+		case hours == 0:
+			return fmt.Sprintf("%dm", minutes)
+		case minutes == 0:
+			return fmt.Sprintf("%dh", hours)
+		default:
+			return fmt.Sprintf("%dh %dm", hours, minutes)
+		}
+	case RuntimeFormatISO8601:
+		hours, minutes := int(r)/60, int(r)%60
+		switch {
+		case hours == 0:
+			return fmt.Sprintf("PT%dM", minutes)
+		case minutes == 0:
+			return fmt.Sprintf("PT%dH", hours)
+		default:
+			return fmt.Sprintf("PT%dH%dM", hours, minutes)
+		}
+	case RuntimeFormatSeconds:
+		return int(r) * 60
+	default:
+		return fmt.Sprintf("%d mins", r)
 	}
+}
 
-	fields := strings.Fields(value)
-	if len(fields) != 2 {
-		return ErrInvalidRuntimeFormat
+// ParseRuntime accepts a bare number of minutes, "<n> mins", "<n> minutes",
+// hours and minutes such as "1h 45m", or an ISO-8601 duration such as
+// "PT1H45M". ISO-8601 seconds are rounded to the nearest minute.
+func ParseRuntime(value string) (Runtime, error) {
+	value = strings.TrimSpace(value)
+
+	if minutes, err := strconv.ParseInt(value, 10, 32); err == nil {
+		return Runtime(minutes), nil
 	}
-	if fields[1] != "mins" {
-		return ErrInvalidRuntimeFormat
+
+	if matches := runtimeISO8601RX.FindStringSubmatch(strings.ToUpper(value)); matches != nil {
+		if matches[1] == "" && matches[2] == "" && matches[3] == "" {
+			return 0, ErrInvalidRuntimeFormat
+		}
+		seconds := atoi(matches[1])*3600 + atoi(matches[2])*60 + atoi(matches[3])
+		return Runtime((seconds + 30) / 60), nil
 	}
 
-	runtime, err := strconv.ParseInt(fields[0], 10, 32)
-	if err != nil {
-		return ErrInvalidRuntimeFormat
+	if matches := runtimeHoursMinutesRX.FindStringSubmatch(strings.ToLower(value)); matches != nil {
+		if matches[1] == "" && matches[2] == "" {
+			return 0, ErrInvalidRuntimeFormat
+		}
+		return Runtime(atoi(matches[1])*60 + atoi(matches[2])), nil
 	}
 
-	*r = Runtime(runtime)
+	return 0, ErrInvalidRuntimeFormat
+}
 
-	return nil
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
 }
@@ -0,0 +1,66 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParseRuntime(t *testing.T) {
+	tbl := []struct {
+		input  string
+		expect Runtime
+		err    error
+	}{
+		{input: "105 mins", expect: 105},
+		{input: "105 minutes", expect: 105},
+		{input: "1 min", expect: 1},
+		{input: "105", expect: 105},
+		{input: "1h 45m", expect: 105},
+		{input: "1h45m", expect: 105},
+		{input: "2 hours", expect: 120},
+		{input: "45m", expect: 45},
+		{input: "PT1H45M", expect: 105},
+		{input: "pt2h", expect: 120},
+		{input: "PT104M40S", expect: 105},
+		{input: "PT", err: ErrInvalidRuntimeFormat},
+		{input: "", err: ErrInvalidRuntimeFormat},
+		{input: "105 secs", err: ErrInvalidRuntimeFormat},
+		{input: "an hour", err: ErrInvalidRuntimeFormat},
+	}
+
+	for i, test := range tbl {
+		t.Run(fmt.Sprintf("Case %d", i+1), func(t *testing.T) {
+			got, err := ParseRuntime(test.input)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got error %v, want %v", err, test.err)
+			}
+			if got != test.expect {
+				t.Fatalf("got %d, want %d", got, test.expect)
+			}
+		})
+	}
+}
+
+func TestRuntimeFormat(t *testing.T) {
+	tbl := []struct {
+		runtime Runtime
+		format  RuntimeFormat
+		expect  any
+	}{
+		{runtime: 105, format: RuntimeFormatMins, expect: "105 mins"},
+		{runtime: 105, format: RuntimeFormatHM, expect: "1h 45m"},
+		{runtime: 120, format: RuntimeFormatHM, expect: "2h"},
+		{runtime: 45, format: RuntimeFormatISO8601, expect: "PT45M"},
+		{runtime: 105, format: RuntimeFormatISO8601, expect: "PT1H45M"},
+		{runtime: 105, format: RuntimeFormatSeconds, expect: 6300},
+	}
+
+	for i, test := range tbl {
+		t.Run(fmt.Sprintf("Case %d", i+1), func(t *testing.T) {
+			if got := test.runtime.Format(test.format); got != test.expect {
+				t.Fatalf("got %v, want %v", got, test.expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package data
+
+import (
+	"strings"
+	"unicode"
+)
+
+var SearchConfigSafeList = []string{"english", "simple"}
+
+var searchColumns = map[string]string{
+	"english": "title_search_english",
+	"simple":  "title_search_simple",
+}
+
+// ParseSearchQuery turns user input into a to_tsquery expression. Terms are
+// ANDed together, "quoted text" becomes a phrase, a leading - negates a term
+// or phrase and a trailing * turns a term into a prefix match.
+func ParseSearchQuery(input string) string {
+	var terms []string
+
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		negate := false
+		if runes[i] == '-' {
+			negate = true
+			i++
+		}
+
+		var raw string
+		phrase := false
+		if i < len(runes) && runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			raw, phrase = string(runes[i+1:end]), true
+			i = end + 1
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) {
+				end++
+			}
+			raw = string(runes[i:end])
+			i = end
+		}
+
+		prefix := !phrase && strings.HasSuffix(raw, "*")
+
+		words := strings.FieldsFunc(raw, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(words) == 0 {
+			continue
+		}
+
+		if prefix {
+			words[len(words)-1] += ":*"
+		}
+
+		term := strings.Join(words, " <-> ")
+		if len(words) > 1 {
+			term = "(" + term + ")"
+		}
+		if negate {
+			term = "!" + term
+		}
+
+		terms = append(terms, term)
+	}
+
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,29 @@
+package data
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tbl := []struct {
+		input  string
+		expect string
+	}{
+		{input: "star wars", expect: "star & wars"},
+		{input: "star*", expect: "star:*"},
+		{input: `"new hope" -empire`, expect: "(new <-> hope) & !empire"},
+		{input: `-"the return"`, expect: "!(the <-> return)"},
+		{input: "spider-man", expect: "(spider <-> man)"},
+		{input: "it's'); DROP", expect: "(it <-> s) & DROP"},
+		{input: "  !!! ", expect: ""},
+	}
+
+	for i, test := range tbl {
+		t.Run(fmt.Sprintf("Case %d", i+1), func(t *testing.T) {
+			if got := ParseSearchQuery(test.input); got != test.expect {
+				t.Fatalf("got %q, want %q", got, test.expect)
+			}
+		})
+	}
+}
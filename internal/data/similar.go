@@ -0,0 +1,119 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+// SimilarityWeights sets how much each signal contributes to a similarity
+// score. Scores are normalised by the sum of the weights that apply, so the
+// rating weight is ignored for movies that have not been rated.
+type SimilarityWeights struct {
+	Genres  float64
+	Year    float64
+	Runtime float64
+	Title   float64
+	Rating  float64
+}
+
+var DefaultSimilarityWeights = SimilarityWeights{
+	Genres:  0.5,
+	Year:    0.15,
+	Runtime: 0.1,
+	Title:   0.15,
+	Rating:  0.1,
+}
+
+func ValidateSimilarityWeights(v *validator.Validator, w SimilarityWeights) {
+	for key, weight := range map[string]float64{
+		"genre_weight":   w.Genres,
+		"year_weight":    w.Year,
+		"runtime_weight": w.Runtime,
+		"title_weight":   w.Title,
+		"rating_weight":  w.Rating,
+	} {
+		v.Check(weight >= 0, key, "must not be negative")
+		v.Check(weight <= 100, key, "must not be more than 100")
+	}
+
+	v.Check(w.Genres+w.Year+w.Runtime+w.Title > 0, "weights", "at least one of the genre, year, runtime or title weights must be greater than zero")
+}
+
+type SimilarMovie struct {
+	Movie *Movie  `json:"movie"`
+	Score float64 `json:"score"`
+}
+
+// GetSimilar scores the movies that share a genre with movie or have a
+// trigram-similar title, so the candidates come from the genres and title
+// indexes rather than a scan of the whole table.
+func (m MovieModel) GetSimilar(movie *Movie, w SimilarityWeights, filters Filters) ([]*SimilarMovie, PageInfo, error) {
+	columns := movieSelection(nil)
+
+	query := fmt.Sprintf(`
+        SELECT count(*) OVER(), %s, m.score
+        FROM (
+            SELECT m.*, (
+                $2::float8 * COALESCE(
+                    cardinality(ARRAY(SELECT unnest(m.genres) INTERSECT SELECT unnest($3::text[])))::float8
+                    / NULLIF(cardinality(ARRAY(SELECT unnest(m.genres) UNION SELECT unnest($3::text[]))), 0),
+                    0
+                )
+                + $4::float8 / (1.0 + abs(m.year - $5) / 5.0)
+                + $6::float8 / (1.0 + abs(m.runtime - $7) / 15.0)
+                + $8::float8 * similarity(m.title, $9)
+                + CASE WHEN m.rating_count > 0 THEN $10::float8 * m.rating / 10.0 ELSE 0 END
+            ) / ($2::float8 + $4::float8 + $6::float8 + $8::float8 + CASE WHEN m.rating_count > 0 THEN $10::float8 ELSE 0 END) AS score
+            FROM movies m
+            WHERE m.id <> $1 AND m.deleted_at IS NULL
+            AND (m.genres && $3::text[] OR m.title %% $9)
+        ) m
+        ORDER BY m.score DESC, m.id ASC
+        LIMIT $11 OFFSET $12
+    `, strings.Join(qualifyColumns("m", columns), ", "))
+
+	args := []any{
+		movie.ID,
+		w.Genres, pq.Array(movie.Genres),
+		w.Year, movie.Year,
+		w.Runtime, movie.Runtime,
+		w.Title, movie.Title,
+		w.Rating,
+		filters.limit(), filters.offset(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	defer rows.Close()
+
+	var totalRecords int
+	movies := []*SimilarMovie{}
+	for rows.Next() {
+		similar := SimilarMovie{Movie: &Movie{}}
+
+		dests := append([]any{&totalRecords}, movieDestinations(similar.Movie, columns)...)
+		if err := rows.Scan(append(dests, &similar.Score)...); err != nil {
+			return nil, PageInfo{}, err
+		}
+
+		movies = append(movies, &similar)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	pageInfo := calculatePageInfo(totalRecords, filters.Page, filters.PageSize)
+
+	return movies, pageInfo, nil
+}
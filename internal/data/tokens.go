@@ -4,7 +4,6 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/sha256"
-	"database/sql"
 	"encoding/base32"
 	"time"
 
@@ -25,7 +24,7 @@ type Token struct {
 }
 
 type TokenModel struct {
-	DB *sql.DB
+	DB DBTX
 }
 
 func generateToken(userID int64, ttl time.Duration, scope string) (*Token, error) {
@@ -0,0 +1,271 @@
+package data
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"greenlight.pvargasb.com/internal/validator"
+)
+
+var localeRX = regexp.MustCompile(`^([a-zA-Z]{2,3})(?:[-_]([a-zA-Z]{4}))?(?:[-_]([a-zA-Z]{2}|[0-9]{3}))?$`)
+
+type Translation struct {
+	MovieID   int       `json:"-"`
+	Locale    string    `json:"locale"`
+	CreatedAt time.Time `json:"-"`
+	Title     string    `json:"title"`
+	Synopsis  string    `json:"synopsis,omitempty"`
+}
+
+func ValidateTranslation(v *validator.Validator, translation *Translation) {
+	v.Check(translation.Locale != "", "locale", "must be provided")
+	v.Check(localeRX.MatchString(translation.Locale), "locale", "must be a language tag such as en, pt-BR or zh-Hant-TW")
+
+	v.Check(translation.Title != "", "title", "must be provided")
+	v.Check(len(translation.Title) <= 500, "title", "must not be more than 500 bytes long")
+
+	v.Check(len(translation.Synopsis) <= 5000, "synopsis", "must not be more than 5000 bytes long")
+}
+
+// NormalizeLocale returns the canonical casing of a language tag, such as
+// "pt-BR" for "PT_br". It reports false if the tag is not of the form
+// language[-script][-region].
+func NormalizeLocale(locale string) (string, bool) {
+	matches := localeRX.FindStringSubmatch(strings.TrimSpace(locale))
+	if matches == nil {
+		return "", false
+	}
+
+	normalized := strings.ToLower(matches[1])
+	if matches[2] != "" {
+		normalized += "-" + strings.ToUpper(matches[2][:1]) + strings.ToLower(matches[2][1:])
+	}
+	if matches[3] != "" {
+		normalized += "-" + strings.ToUpper(matches[3])
+	}
+
+	return normalized, true
+}
+
+func localeLanguage(locale string) string {
+	language, _, _ := strings.Cut(locale, "-")
+	return strings.ToLower(language)
+}
+
+// ParseAcceptLanguage returns the locales in an Accept-Language header, most
+// preferred first. Locales with a quality of zero and malformed entries are
+// dropped.
+func ParseAcceptLanguage(header string) []string {
+	type preference struct {
+		locale  string
+		quality float64
+	}
+
+	var preferences []preference
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+
+		quality := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || q < 0 || q > 1 {
+				continue
+			}
+			quality = q
+		}
+		if quality == 0 {
+			continue
+		}
+
+		locale, ok := NormalizeLocale(tag)
+		if !ok {
+			continue
+		}
+
+		preferences = append(preferences, preference{locale, quality})
+	}
+
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+
+	locales := make([]string, 0, len(preferences))
+	for _, p := range preferences {
+		if !slices.Contains(locales, p.locale) {
+			locales = append(locales, p.locale)
+		}
+	}
+
+	return locales
+}
+
+// BestTranslation picks the translation that best matches the preferred
+// locales. For each locale in turn an exact match wins, followed by a
+// translation for the bare language and then one for any other region of the
+// same language. It returns nil if nothing matches.
+func BestTranslation(translations []*Translation, preferred []string) *Translation {
+	for _, locale := range preferred {
+		language := localeLanguage(locale)
+
+		var sameLanguage *Translation
+		for _, t := range translations {
+			switch {
+			case strings.EqualFold(t.Locale, locale):
+				return t
+			case localeLanguage(t.Locale) != language:
+			case strings.EqualFold(t.Locale, language):
+				sameLanguage = t
+			case sameLanguage == nil:
+				sameLanguage = t
+			}
+		}
+
+		if sameLanguage != nil {
+			return sameLanguage
+		}
+	}
+
+	return nil
+}
+
+type TranslationModel struct {
+	DB DBTX
+}
+
+// Upsert creates or replaces the translation for its movie and locale. It
+// reports whether a new translation was created.
+func (m TranslationModel) Upsert(translation *Translation) (bool, error) {
+	query := `
+        INSERT INTO movie_translations (movie_id, locale, title, synopsis)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (movie_id, locale) DO UPDATE
+        SET title = EXCLUDED.title, synopsis = EXCLUDED.synopsis
+        RETURNING created_at, xmax = 0
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var inserted bool
+	err := m.DB.QueryRowContext(
+		ctx,
+		query,
+		translation.MovieID,
+		translation.Locale,
+		translation.Title,
+		translation.Synopsis,
+	).Scan(
+		&translation.CreatedAt,
+		&inserted,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return inserted, nil
+}
+
+func (m TranslationModel) Delete(movieID int, locale string) error {
+	query := `
+        DELETE FROM movie_translations
+        WHERE movie_id = $1 AND locale = $2
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, movieID, locale)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m TranslationModel) GetAllForMovie(movieID int) ([]*Translation, error) {
+	query := `
+        SELECT movie_id, locale, created_at, title, synopsis
+        FROM movie_translations
+        WHERE movie_id = $1
+        ORDER BY locale
+    `
+
+	translations, err := m.query(query, movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	if translations[movieID] == nil {
+		return []*Translation{}, nil
+	}
+
+	return translations[movieID], nil
+}
+
+// GetForMovies returns the translations of the given movies into any of the
+// given locales' languages, keyed by movie ID.
+func (m TranslationModel) GetForMovies(movieIDs []int, locales []string) (map[int][]*Translation, error) {
+	languages := make([]string, len(locales))
+	for i, locale := range locales {
+		languages[i] = localeLanguage(locale)
+	}
+
+	query := `
+        SELECT movie_id, locale, created_at, title, synopsis
+        FROM movie_translations
+        WHERE movie_id = ANY($1) AND lower(split_part(locale, '-', 1)) = ANY($2)
+        ORDER BY movie_id, locale
+    `
+
+	return m.query(query, pq.Array(movieIDs), pq.Array(languages))
+}
+
+func (m TranslationModel) query(query string, args ...any) (map[int][]*Translation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := make(map[int][]*Translation)
+	for rows.Next() {
+		var translation Translation
+
+		err := rows.Scan(
+			&translation.MovieID,
+			&translation.Locale,
+			&translation.CreatedAt,
+			&translation.Title,
+			&translation.Synopsis,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		translations[translation.MovieID] = append(translations[translation.MovieID], &translation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return translations, nil
+}
@@ -0,0 +1,65 @@
+package data
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tbl := []struct {
+		header string
+		expect []string
+	}{
+		{header: "", expect: []string{}},
+		{header: "pt-br", expect: []string{"pt-BR"}},
+		{header: "fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5", expect: []string{"fr-CH", "fr", "en", "de"}},
+		{header: "en;q=0.5, es", expect: []string{"es", "en"}},
+		{header: "de;q=0, it", expect: []string{"it"}},
+		{header: "zh-hant-tw, en_GB;q=0.8", expect: []string{"zh-Hant-TW", "en-GB"}},
+		{header: "en;q=abc, es, es;q=0.1", expect: []string{"es"}},
+	}
+
+	for i, test := range tbl {
+		t.Run(fmt.Sprintf("Case %d", i+1), func(t *testing.T) {
+			if got := ParseAcceptLanguage(test.header); !slices.Equal(got, test.expect) {
+				t.Fatalf("got %q, want %q", got, test.expect)
+			}
+		})
+	}
+}
+
+func TestBestTranslation(t *testing.T) {
+	translations := []*Translation{
+		{Locale: "es-MX", Title: "Mexican Spanish"},
+		{Locale: "es", Title: "Spanish"},
+		{Locale: "pt-BR", Title: "Brazilian Portuguese"},
+		{Locale: "fr", Title: "French"},
+	}
+
+	tbl := []struct {
+		preferred []string
+		expect    string
+	}{
+		{preferred: []string{"es-MX"}, expect: "Mexican Spanish"},
+		{preferred: []string{"es-AR"}, expect: "Spanish"},
+		{preferred: []string{"es"}, expect: "Spanish"},
+		{preferred: []string{"pt-PT"}, expect: "Brazilian Portuguese"},
+		{preferred: []string{"fr-CA", "es"}, expect: "French"},
+		{preferred: []string{"de", "pt"}, expect: "Brazilian Portuguese"},
+		{preferred: []string{"de", "it"}, expect: ""},
+		{preferred: nil, expect: ""},
+	}
+
+	for i, test := range tbl {
+		t.Run(fmt.Sprintf("Case %d", i+1), func(t *testing.T) {
+			var got string
+			if translation := BestTranslation(translations, test.preferred); translation != nil {
+				got = translation.Title
+			}
+			if got != test.expect {
+				t.Fatalf("got %q, want %q", got, test.expect)
+			}
+		})
+	}
+}
@@ -16,7 +16,7 @@ var ErrDuplicateEmail = errors.New("duplicate emil")
 var AnonymousUser = &User{}
 
 type UserModel struct {
-	DB *sql.DB
+	DB DBTX
 }
 
 type User struct {
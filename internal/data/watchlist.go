@@ -0,0 +1,137 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type WatchlistEntry struct {
+	Movie   *Movie    `json:"movie"`
+	AddedAt time.Time `json:"added_at"`
+	Watched bool      `json:"watched"`
+	Note    string    `json:"note"`
+}
+
+type WatchlistModel struct {
+	DB DBTX
+}
+
+func (m WatchlistModel) Upsert(userID int64, movieID int, watched *bool, note *string) (*WatchlistEntry, bool, error) {
+	query := `
+        INSERT INTO watchlist_entries (user_id, movie_id, watched, note)
+        VALUES ($1, $2, COALESCE($3::boolean, false), COALESCE($4::text, ''))
+        ON CONFLICT (user_id, movie_id) DO UPDATE
+        SET watched = COALESCE($3::boolean, watchlist_entries.watched),
+            note = COALESCE($4::text, watchlist_entries.note)
+        RETURNING added_at, watched, note, xmax = 0
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var entry WatchlistEntry
+	var inserted bool
+	err := m.DB.QueryRowContext(ctx, query, userID, movieID, watched, note).Scan(
+		&entry.AddedAt,
+		&entry.Watched,
+		&entry.Note,
+		&inserted,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &entry, inserted, nil
+}
+
+func (m WatchlistModel) Delete(userID int64, movieID int) error {
+	query := `
+        DELETE FROM watchlist_entries
+        WHERE user_id = $1 AND movie_id = $2
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, userID, movieID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m WatchlistModel) Contains(userID int64, movieID int) (bool, error) {
+	query := `
+        SELECT EXISTS (
+            SELECT 1 FROM watchlist_entries WHERE user_id = $1 AND movie_id = $2
+        )
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var exists bool
+	if err := m.DB.QueryRowContext(ctx, query, userID, movieID).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (m WatchlistModel) GetAllForUser(userID int64, watched *bool, filters Filters) ([]*WatchlistEntry, PageInfo, error) {
+	columns := movieSelection(nil)
+
+	query := fmt.Sprintf(`
+        SELECT count(*) OVER(), %s, w.added_at, w.watched, w.note
+        FROM watchlist_entries w
+        INNER JOIN movies m ON m.id = w.movie_id
+        WHERE w.user_id = $1
+        AND m.deleted_at IS NULL
+        AND (w.watched = $2 OR $2 IS NULL)
+        ORDER BY %s %s, m.id ASC
+        LIMIT $3 OFFSET $4
+    `, strings.Join(qualifyColumns("m", columns), ", "), filters.sortColumn(), filters.sortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID, watched, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	defer rows.Close()
+
+	var totalRecords int
+	entries := []*WatchlistEntry{}
+	for rows.Next() {
+		entry := WatchlistEntry{Movie: &Movie{}}
+
+		dests := append([]any{&totalRecords}, movieDestinations(entry.Movie, columns)...)
+		err := rows.Scan(append(dests, &entry.AddedAt, &entry.Watched, &entry.Note)...)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, err
+	}
+
+	pageInfo := calculatePageInfo(totalRecords, filters.Page, filters.PageSize)
+
+	return entries, pageInfo, nil
+}
@@ -0,0 +1,94 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+var contentTypes = map[string]string{
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+	"webp": "image/webp",
+}
+
+type Config struct {
+	Format      string
+	ContentType string
+	Width       int
+	Height      int
+}
+
+func DecodeConfig(b []byte) (Config, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		switch {
+		case errors.Is(err, image.ErrFormat):
+			return Config{}, ErrUnsupportedFormat
+		default:
+			return Config{}, err
+		}
+	}
+
+	contentType, ok := contentTypes[format]
+	if !ok {
+		return Config{}, ErrUnsupportedFormat
+	}
+
+	return Config{
+		Format:      format,
+		ContentType: contentType,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}, nil
+}
+
+func Decode(b []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(b))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, ErrUnsupportedFormat
+	}
+
+	return img, err
+}
+
+// Resize scales img down to the given width, keeping its aspect ratio.
+func Resize(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	return dst
+}
+
+// Encode writes img as PNG when the source was a PNG and as JPEG otherwise,
+// returning the file extension used.
+func Encode(w io.Writer, img image.Image, format string) (string, error) {
+	if format == "png" {
+		return "png", png.Encode(w, img)
+	}
+
+	return "jpg", jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+}
+
+func Extension(format string) string {
+	switch format {
+	case "jpeg":
+		return "jpg"
+	default:
+		return format
+	}
+}
@@ -0,0 +1,54 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func TestDecodeConfigAndResize(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 400, 600))); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := DecodeConfig(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ContentType != "image/png" || cfg.Width != 400 || cfg.Height != 600 {
+		t.Fatalf("got %+v", cfg)
+	}
+
+	img, err := Decode(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tbl := []struct {
+		width  int
+		expect image.Point
+	}{
+		{width: 200, expect: image.Pt(200, 300)},
+		{width: 92, expect: image.Pt(92, 138)},
+		{width: 1, expect: image.Pt(1, 1)},
+	}
+
+	for i, test := range tbl {
+		t.Run(fmt.Sprintf("Case %d", i+1), func(t *testing.T) {
+			if got := Resize(img, test.width).Bounds().Size(); got != test.expect {
+				t.Fatalf("got %v, want %v", got, test.expect)
+			}
+		})
+	}
+}
+
+func TestDecodeConfigUnsupported(t *testing.T) {
+	_, err := DecodeConfig([]byte("GIF89a not really"))
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("got %v, want ErrUnsupportedFormat", err)
+	}
+}
@@ -0,0 +1,278 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrTestFailed       = errors.New("test operation failed")
+	ErrPathNotFound     = errors.New("path not found")
+	ErrInvalidPath      = errors.New("invalid path")
+	ErrInvalidOperation = errors.New("invalid operation")
+)
+
+// Operation is a single RFC 6902 operation. Only add, remove, replace and
+// test are supported.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Apply applies the operations to doc in order. Either every operation is
+// applied or an error is returned and doc is left untouched.
+func Apply(doc []byte, ops []Operation) ([]byte, error) {
+	var node any
+	if err := json.Unmarshal(doc, &node); err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		var err error
+		if node, err = apply(node, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %q): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	return json.Marshal(node)
+}
+
+// MergePatch applies an RFC 7396 merge patch to doc.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(merge(target, p))
+}
+
+func merge(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+		} else {
+			t[key] = merge(t[key], value)
+		}
+	}
+
+	return t
+}
+
+func apply(node any, op Operation) (any, error) {
+	tokens, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("%w: missing value", ErrInvalidOperation)
+		}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidOperation, err)
+		}
+	case "remove":
+	default:
+		return nil, fmt.Errorf("%w: unsupported op %q", ErrInvalidOperation, op.Op)
+	}
+
+	if len(tokens) == 0 {
+		switch op.Op {
+		case "add", "replace":
+			return value, nil
+		case "test":
+			if !reflect.DeepEqual(node, value) {
+				return nil, ErrTestFailed
+			}
+			return node, nil
+		default:
+			return nil, fmt.Errorf("%w: cannot remove the whole document", ErrInvalidOperation)
+		}
+	}
+
+	return update(node, tokens, func(parent any, key string) (any, error) {
+		switch op.Op {
+		case "add":
+			return add(parent, key, value)
+		case "remove":
+			return remove(parent, key)
+		case "replace":
+			return replace(parent, key, value)
+		default:
+			current, err := get(parent, key)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, ErrTestFailed
+			}
+			return parent, nil
+		}
+	})
+}
+
+// update walks to the parent of the last token and replaces it with the
+// result of fn, rebuilding slices on the way back up.
+func update(node any, tokens []string, fn func(parent any, key string) (any, error)) (any, error) {
+	if len(tokens) == 1 {
+		return fn(node, tokens[0])
+	}
+
+	child, err := get(node, tokens[0])
+	if err != nil {
+		return nil, err
+	}
+
+	child, err = update(child, tokens[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n := node.(type) {
+	case map[string]any:
+		n[tokens[0]] = child
+	case []any:
+		i, _ := strconv.Atoi(tokens[0])
+		n[i] = child
+	}
+
+	return node, nil
+}
+
+func get(node any, key string) (any, error) {
+	switch n := node.(type) {
+	case map[string]any:
+		value, ok := n[key]
+		if !ok {
+			return nil, ErrPathNotFound
+		}
+		return value, nil
+	case []any:
+		i, err := index(key, len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+		return n[i], nil
+	default:
+		return nil, ErrPathNotFound
+	}
+}
+
+func add(node any, key string, value any) (any, error) {
+	switch n := node.(type) {
+	case map[string]any:
+		n[key] = value
+		return n, nil
+	case []any:
+		if key == "-" {
+			return append(n, value), nil
+		}
+		i, err := index(key, len(n))
+		if err != nil {
+			return nil, err
+		}
+		n = append(n, nil)
+		copy(n[i+1:], n[i:])
+		n[i] = value
+		return n, nil
+	default:
+		return nil, ErrPathNotFound
+	}
+}
+
+func remove(node any, key string) (any, error) {
+	switch n := node.(type) {
+	case map[string]any:
+		if _, ok := n[key]; !ok {
+			return nil, ErrPathNotFound
+		}
+		delete(n, key)
+		return n, nil
+	case []any:
+		i, err := index(key, len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+		return append(n[:i], n[i+1:]...), nil
+	default:
+		return nil, ErrPathNotFound
+	}
+}
+
+func replace(node any, key string, value any) (any, error) {
+	switch n := node.(type) {
+	case map[string]any:
+		if _, ok := n[key]; !ok {
+			return nil, ErrPathNotFound
+		}
+		n[key] = value
+		return n, nil
+	case []any:
+		i, err := index(key, len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = value
+		return n, nil
+	default:
+		return nil, ErrPathNotFound
+	}
+}
+
+func index(key string, last int) (int, error) {
+	if key == "-" {
+		return 0, ErrPathNotFound
+	}
+
+	if key == "" || (len(key) > 1 && key[0] == '0') {
+		return 0, ErrInvalidPath
+	}
+
+	i, err := strconv.Atoi(key)
+	if err != nil || i < 0 {
+		return 0, ErrInvalidPath
+	}
+	if i > last {
+		return 0, ErrPathNotFound
+	}
+
+	return i, nil
+}
+
+func parsePointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		return nil, ErrInvalidPath
+	}
+
+	tokens := strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
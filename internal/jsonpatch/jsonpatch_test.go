@@ -0,0 +1,119 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+const movie = `{"id":1,"title":"Casablanca","year":1942,"genres":["drama","romance","war"],"version":3}`
+
+func TestApply(t *testing.T) {
+	tbl := []struct {
+		ops    string
+		expect string
+		err    error
+	}{
+		{
+			ops:    `[{"op":"remove","path":"/genres/1"}]`,
+			expect: `{"id":1,"title":"Casablanca","year":1942,"genres":["drama","war"],"version":3}`,
+		},
+		{
+			ops:    `[{"op":"add","path":"/genres/-","value":"classic"},{"op":"add","path":"/genres/0","value":"noir"}]`,
+			expect: `{"id":1,"title":"Casablanca","year":1942,"genres":["noir","drama","romance","war","classic"],"version":3}`,
+		},
+		{
+			ops:    `[{"op":"test","path":"/version","value":3},{"op":"replace","path":"/year","value":null}]`,
+			expect: `{"id":1,"title":"Casablanca","year":null,"genres":["drama","romance","war"],"version":3}`,
+		},
+		{
+			ops:    `[{"op":"replace","path":"/genres/2","value":"history"},{"op":"remove","path":"/title"}]`,
+			expect: `{"id":1,"year":1942,"genres":["drama","romance","history"],"version":3}`,
+		},
+		{
+			ops: `[{"op":"replace","path":"/title","value":"x"},{"op":"test","path":"/version","value":2}]`,
+			err: ErrTestFailed,
+		},
+		{
+			ops: `[{"op":"remove","path":"/genres/3"}]`,
+			err: ErrPathNotFound,
+		},
+		{
+			ops: `[{"op":"replace","path":"/runtime","value":102}]`,
+			err: ErrPathNotFound,
+		},
+		{
+			ops: `[{"op":"add","path":"/genres/01","value":"x"}]`,
+			err: ErrInvalidPath,
+		},
+		{
+			ops: `[{"op":"move","path":"/title"}]`,
+			err: ErrInvalidOperation,
+		},
+		{
+			ops: `[{"op":"add","path":"/title"}]`,
+			err: ErrInvalidOperation,
+		},
+	}
+
+	for i, test := range tbl {
+		t.Run(fmt.Sprintf("Case %d", i+1), func(t *testing.T) {
+			var ops []Operation
+			if err := json.Unmarshal([]byte(test.ops), &ops); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Apply([]byte(movie), ops)
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Fatalf("got error %v, want %v", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertJSONEqual(t, got, test.expect)
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	tbl := []struct {
+		patch  string
+		expect string
+	}{
+		{patch: `{"title":"Casablanca (1942)"}`, expect: `{"id":1,"title":"Casablanca (1942)","year":1942,"genres":["drama","romance","war"],"version":3}`},
+		{patch: `{"year":null,"genres":["drama"]}`, expect: `{"id":1,"title":"Casablanca","genres":["drama"],"version":3}`},
+		{patch: `{}`, expect: movie},
+	}
+
+	for i, test := range tbl {
+		t.Run(fmt.Sprintf("Case %d", i+1), func(t *testing.T) {
+			got, err := MergePatch([]byte(movie), []byte(test.patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertJSONEqual(t, got, test.expect)
+		})
+	}
+}
+
+func assertJSONEqual(t *testing.T, got []byte, expect string) {
+	t.Helper()
+
+	var a, b any
+	if err := json.Unmarshal(got, &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(expect), &b); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		t.Fatalf("got %s, want %s", got, expect)
+	}
+}
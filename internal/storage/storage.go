@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage persists uploaded files under slash-separated keys such as
+// "movies/1/poster-ab12/original.jpg".
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Delete(ctx context.Context, prefix string) error
+	URL(key string) string
+}
+
+type Local struct {
+	root    string
+	baseURL string
+}
+
+func NewLocal(root, baseURL string) (*Local, error) {
+	if _, err := url.Parse(baseURL); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &Local{root: root, baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+func (l *Local) Put(ctx context.Context, key string, r io.Reader) error {
+	filename, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
+
+func (l *Local) Delete(ctx context.Context, prefix string) error {
+	filename, err := l.path(prefix)
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(filename)
+}
+
+func (l *Local) URL(key string) string {
+	return l.baseURL + "/" + key
+}
+
+// MountPath returns the path of the base URL, which is where ServeHTTP should
+// be mounted. It is empty when the base URL has no path, in which case files
+// are expected to be served by something other than this server.
+func (l *Local) MountPath() string {
+	u, err := url.Parse(l.baseURL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// ServeHTTP serves stored files by key without exposing directory listings.
+func (l *Local) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filename, err := l.path(strings.TrimPrefix(r.URL.Path, "/"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	http.ServeFile(w, r, filename)
+}
+
+func (l *Local) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if key == "" || cleaned == "/" || cleaned != "/"+key {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(l.root, filepath.FromSlash(cleaned)), nil
+}
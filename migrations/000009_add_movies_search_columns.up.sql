@@ -0,0 +1,10 @@
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS title_search_simple tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', title)) STORED;
+
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS title_search_english tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', title)) STORED;
+
+CREATE INDEX IF NOT EXISTS movies_title_search_simple_idx ON movies USING GIN (title_search_simple);
+CREATE INDEX IF NOT EXISTS movies_title_search_english_idx ON movies USING GIN (title_search_english);
+
+DROP INDEX IF EXISTS movies_title_idx;
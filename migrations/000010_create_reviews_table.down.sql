@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS reviews;
+
+DROP FUNCTION IF EXISTS reviews_refresh_movie_rating();
+DROP FUNCTION IF EXISTS refresh_movie_rating(bigint);
+
+DROP INDEX IF EXISTS movies_rating_idx;
+
+ALTER TABLE movies DROP COLUMN IF EXISTS rating_count;
+ALTER TABLE movies DROP COLUMN IF EXISTS rating;
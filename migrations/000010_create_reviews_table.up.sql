@@ -0,0 +1,48 @@
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS rating numeric(4, 2) NOT NULL DEFAULT 0;
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS rating_count integer NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS movies_rating_idx ON movies (rating, id);
+
+CREATE TABLE IF NOT EXISTS reviews (
+    id bigserial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    rating integer NOT NULL CHECK (rating BETWEEN 1 AND 10),
+    body text NOT NULL DEFAULT '',
+    version integer NOT NULL DEFAULT 1,
+    UNIQUE (movie_id, user_id)
+);
+
+-- Keep the denormalised rating columns on movies in sync with the reviews.
+CREATE OR REPLACE FUNCTION refresh_movie_rating(target bigint) RETURNS void AS $$
+    UPDATE movies
+    SET (rating, rating_count) = (
+        SELECT COALESCE(avg(rating), 0), count(*)
+        FROM reviews
+        WHERE movie_id = target
+    )
+    WHERE id = target;
+$$ LANGUAGE sql;
+
+CREATE OR REPLACE FUNCTION reviews_refresh_movie_rating() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        PERFORM refresh_movie_rating(NEW.movie_id);
+    ELSIF TG_OP = 'DELETE' THEN
+        PERFORM refresh_movie_rating(OLD.movie_id);
+    ELSE
+        PERFORM refresh_movie_rating(NEW.movie_id);
+        IF OLD.movie_id <> NEW.movie_id THEN
+            PERFORM refresh_movie_rating(OLD.movie_id);
+        END IF;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER reviews_refresh_movie_rating
+AFTER INSERT OR UPDATE OR DELETE ON reviews
+FOR EACH ROW EXECUTE FUNCTION reviews_refresh_movie_rating();
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS movies_clear_watchlist_entries ON movies;
+DROP FUNCTION IF EXISTS movies_clear_watchlist_entries();
+
+DROP TABLE IF EXISTS watchlist_entries;
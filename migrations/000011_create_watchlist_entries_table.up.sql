@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS watchlist_entries (
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    added_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    watched bool NOT NULL DEFAULT false,
+    note text NOT NULL DEFAULT '',
+    PRIMARY KEY (user_id, movie_id)
+);
+
+CREATE INDEX IF NOT EXISTS watchlist_entries_movie_id_idx ON watchlist_entries (movie_id);
+
+-- Movies are soft deleted, so the foreign key cascade alone would leave
+-- entries behind until the movie is purged.
+CREATE OR REPLACE FUNCTION movies_clear_watchlist_entries() RETURNS trigger AS $$
+BEGIN
+    DELETE FROM watchlist_entries WHERE movie_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER movies_clear_watchlist_entries
+AFTER UPDATE OF deleted_at ON movies
+FOR EACH ROW
+WHEN (OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL)
+EXECUTE FUNCTION movies_clear_watchlist_entries();
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS genres (
+    id bigserial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    slug text NOT NULL UNIQUE,
+    name text NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS genre_aliases (
+    alias text PRIMARY KEY,
+    genre_id bigint NOT NULL REFERENCES genres ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS genre_aliases_genre_id_idx ON genre_aliases (genre_id);
+
+-- Rewrite existing genres as canonical slugs, keeping the first occurrence of each.
+UPDATE movies SET genres = ARRAY(
+    SELECT s.slug FROM (
+        SELECT trim(both '-' from regexp_replace(lower(g), '[^[:alnum:]]+', '-', 'g')) AS slug, min(n) AS n
+        FROM unnest(genres) WITH ORDINALITY AS t(g, n)
+        GROUP BY 1
+    ) s
+    ORDER BY s.n
+);
+
+INSERT INTO genres (slug, name)
+SELECT g, initcap(replace(g, '-', ' '))
+FROM (SELECT DISTINCT unnest(genres) AS g FROM movies) s
+WHERE g <> ''
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO permissions (code)
+VALUES ('genres:write');
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS movie_images (
+    id bigserial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    kind text NOT NULL,
+    content_type text NOT NULL,
+    width integer NOT NULL,
+    height integer NOT NULL,
+    storage_prefix text NOT NULL,
+    url text NOT NULL,
+    thumbnails jsonb NOT NULL DEFAULT '{}'
+);
+
+ALTER TABLE movie_images ADD CONSTRAINT movie_images_kind_check CHECK (kind IN ('poster', 'still'));
+
+CREATE INDEX IF NOT EXISTS movie_images_movie_id_idx ON movie_images (movie_id);
+CREATE UNIQUE INDEX IF NOT EXISTS movie_images_poster_idx ON movie_images (movie_id) WHERE kind = 'poster';
+
+-- The current poster is copied onto the movie so it can be selected with the
+-- rest of the movie columns.
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS poster jsonb;
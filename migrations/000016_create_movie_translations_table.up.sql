@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS movie_translations (
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    locale text NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    title text NOT NULL,
+    synopsis text NOT NULL DEFAULT '',
+    title_search_simple tsvector GENERATED ALWAYS AS (to_tsvector('simple', title)) STORED,
+    PRIMARY KEY (movie_id, locale)
+);
+
+CREATE INDEX IF NOT EXISTS movie_translations_title_search_simple_idx ON movie_translations USING GIN (title_search_simple);
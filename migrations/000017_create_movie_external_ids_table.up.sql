@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS movie_external_ids (
+    source text NOT NULL,
+    external_id text NOT NULL,
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (source, external_id),
+    UNIQUE (movie_id, source)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS movies_normalized_title_year_idx;
+DROP FUNCTION IF EXISTS normalize_title(text);
+DROP TABLE IF EXISTS movie_redirects;
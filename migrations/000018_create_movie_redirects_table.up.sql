@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS movie_redirects (
+    from_id bigint PRIMARY KEY,
+    to_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS movie_redirects_to_id_idx ON movie_redirects (to_id);
+
+-- Titles are compared ignoring case, punctuation and whitespace when looking
+-- for duplicates.
+CREATE OR REPLACE FUNCTION normalize_title(title text) RETURNS text AS $$
+    SELECT lower(regexp_replace(title, '[^[:alnum:]]+', '', 'g'));
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE INDEX IF NOT EXISTS movies_normalized_title_year_idx ON movies (normalize_title(title), year) WHERE deleted_at IS NULL;
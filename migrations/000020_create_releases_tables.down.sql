@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS movie_certifications;
+DROP TABLE IF EXISTS certifications;
+DROP TABLE IF EXISTS movie_releases;
+
+ALTER TABLE movies DROP CONSTRAINT IF EXISTS movies_year_check;
+ALTER TABLE movies ADD CONSTRAINT movies_year_check CHECK (year BETWEEN 1888 AND date_part('year', now()));
+ALTER TABLE movies DROP COLUMN IF EXISTS announced;
@@ -0,0 +1,48 @@
+-- Announced titles may have a release year up to ten years in the future.
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS announced boolean NOT NULL DEFAULT false;
+
+ALTER TABLE movies DROP CONSTRAINT IF EXISTS movies_year_check;
+ALTER TABLE movies ADD CONSTRAINT movies_year_check CHECK (
+    year >= 1888 AND year <= date_part('year', now()) + CASE WHEN announced THEN 10 ELSE 0 END
+);
+
+CREATE TABLE IF NOT EXISTS movie_releases (
+    id bigserial PRIMARY KEY,
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    country text NOT NULL,
+    kind text NOT NULL,
+    release_date date NOT NULL,
+    note text NOT NULL DEFAULT '',
+    UNIQUE (movie_id, country, kind)
+);
+
+ALTER TABLE movie_releases ADD CONSTRAINT movie_releases_kind_check CHECK (kind IN ('theatrical', 'digital', 'physical'));
+
+CREATE INDEX IF NOT EXISTS movie_releases_country_date_idx ON movie_releases (country, release_date);
+
+-- Certifications are ranked by the minimum age they allow, so they can be
+-- compared within a country.
+CREATE TABLE IF NOT EXISTS certifications (
+    country text NOT NULL,
+    certification text NOT NULL,
+    min_age integer NOT NULL,
+    PRIMARY KEY (country, certification)
+);
+
+INSERT INTO certifications (country, certification, min_age)
+VALUES
+    ('US', 'G', 0), ('US', 'PG', 8), ('US', 'PG-13', 13), ('US', 'R', 17), ('US', 'NC-17', 18),
+    ('GB', 'U', 0), ('GB', 'PG', 8), ('GB', '12A', 12), ('GB', '12', 12), ('GB', '15', 15), ('GB', '18', 18), ('GB', 'R18', 18),
+    ('DE', '0', 0), ('DE', '6', 6), ('DE', '12', 12), ('DE', '16', 16), ('DE', '18', 18),
+    ('FR', 'TP', 0), ('FR', '12', 12), ('FR', '16', 16), ('FR', '18', 18),
+    ('ES', 'A', 0), ('ES', '7', 7), ('ES', '12', 12), ('ES', '16', 16), ('ES', '18', 18),
+    ('BR', 'L', 0), ('BR', '10', 10), ('BR', '12', 12), ('BR', '14', 14), ('BR', '16', 16), ('BR', '18', 18)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS movie_certifications (
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    country text NOT NULL,
+    certification text NOT NULL,
+    PRIMARY KEY (movie_id, country),
+    FOREIGN KEY (country, certification) REFERENCES certifications ON UPDATE CASCADE
+);
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS movies_title_trgm_gist_idx ON movies USING GIST (title gist_trgm_ops) WHERE deleted_at IS NULL;
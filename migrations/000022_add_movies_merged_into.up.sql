@@ -0,0 +1,6 @@
+-- Merged movies are kept in the trash so their revisions survive, but they
+-- can't be restored and aren't purged until the movie they were merged into
+-- is.
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS merged_into bigint REFERENCES movies ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS movies_merged_into_idx ON movies (merged_into) WHERE merged_into IS NOT NULL;